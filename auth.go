@@ -0,0 +1,191 @@
+// Package main - Este archivo contiene los modos de autenticación con OCI
+// El watcher suele correr en la misma instancia A1 que vigila, donde instance
+// principal evita tener una API Key en disco
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/common/auth"
+)
+
+// Modos de autenticación (OCI_AUTH_MODE)
+const (
+	AuthModeAPIKey            = "api_key"            // variables OCI_* y fichero de clave (por defecto)
+	AuthModeInstancePrincipal = "instance_principal" // identidad de la propia instancia (dynamic group)
+	AuthModeConfigFile        = "config_file"        // perfil de ~/.oci/config
+	AuthModeSecurityToken     = "security_token"     // sesión de "oci session authenticate"
+)
+
+// authModes son los modos aceptados
+var authModes = []string{AuthModeAPIKey, AuthModeInstancePrincipal, AuthModeConfigFile, AuthModeSecurityToken}
+
+// currentAuthMode devuelve el modo configurado en OCI_AUTH_MODE
+func currentAuthMode() string {
+	return strings.ToLower(getEnv("OCI_AUTH_MODE", AuthModeAPIKey))
+}
+
+// validAuthMode indica si el modo es uno de los soportados
+func validAuthMode(mode string) bool {
+	for _, m := range authModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// ociConfigFile devuelve la ruta y el perfil del fichero de configuración de OCI
+func ociConfigFile() (string, string) {
+	path := os.Getenv("OCI_CONFIG_FILE")
+	if path == "" {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, ".oci", "config")
+	}
+	return path, getEnv("OCI_CONFIG_PROFILE", "DEFAULT")
+}
+
+// privateKeyPassphrase lee la passphrase de la clave privada
+// OCI_PRIVATE_KEY_PASSPHRASE_FILE permite usar Docker secrets en lugar de una variable
+func privateKeyPassphrase() (string, error) {
+	if path := os.Getenv("OCI_PRIVATE_KEY_PASSPHRASE_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("error reading private key passphrase: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return os.Getenv("OCI_PRIVATE_KEY_PASSPHRASE"), nil
+}
+
+// authRequiredEnv devuelve las variables obligatorias de cada modo
+// Con instance principal o fichero de configuración la tenancy y la región
+// salen del propio proveedor
+func authRequiredEnv(mode string) []string {
+	if mode == AuthModeAPIKey {
+		return []string{"OCI_TENANCY_ID", "OCI_USER_ID", "OCI_FINGERPRINT", "OCI_PRIVATE_KEY_PATH", "OCI_REGION"}
+	}
+	return nil
+}
+
+// instancePrincipal guarda el proveedor de instance principal
+// El SDK renueva sus tokens solo: crearlo en cada recolección llamaría al servicio
+// de metadatos de la instancia cada vez
+var instancePrincipal struct {
+	sync.Mutex
+	provider common.ConfigurationProvider
+}
+
+// apiKeyProvider crea el proveedor a partir de las variables OCI_*
+func apiKeyProvider() (common.ConfigurationProvider, error) {
+	tenancy := os.Getenv("OCI_TENANCY_ID")
+	user := os.Getenv("OCI_USER_ID")
+	fingerprint := os.Getenv("OCI_FINGERPRINT")
+	privateKeyPath := os.Getenv("OCI_PRIVATE_KEY_PATH")
+	region := os.Getenv("OCI_REGION")
+
+	// Leer la clave privada desde el archivo
+	privateKeyBytes, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading private key: %w", err)
+	}
+
+	// La passphrase solo se pasa si la clave está cifrada
+	passphrase, err := privateKeyPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	var passphrasePtr *string
+	if passphrase != "" {
+		passphrasePtr = common.String(passphrase)
+	}
+
+	// Crear el proveedor de configuración
+	// common.NewRawConfigurationProvider es una función del SDK de OCI
+	provider := common.NewRawConfigurationProvider(
+		tenancy,
+		user,
+		region,
+		fingerprint,
+		string(privateKeyBytes),
+		passphrasePtr,
+	)
+
+	return provider, nil
+}
+
+// instancePrincipalProvider devuelve el proveedor de instance principal (en caché)
+// OCI_REGION, si está definida, sustituye a la región de la instancia
+func instancePrincipalProvider() (common.ConfigurationProvider, error) {
+	instancePrincipal.Lock()
+	defer instancePrincipal.Unlock()
+
+	if instancePrincipal.provider == nil {
+		provider, err := auth.InstancePrincipalConfigurationProvider()
+		if err != nil {
+			return nil, fmt.Errorf("error creating instance principal provider: %w", err)
+		}
+		instancePrincipal.provider = provider
+	}
+
+	if region := os.Getenv("OCI_REGION"); region != "" {
+		return regionProvider{instancePrincipal.provider, region}, nil
+	}
+	return instancePrincipal.provider, nil
+}
+
+// configFileProvider crea el proveedor a partir de un perfil de ~/.oci/config
+// Con sessionToken=true usa el security_token_file del perfil
+func configFileProvider(sessionToken bool) (common.ConfigurationProvider, error) {
+	path, profile := ociConfigFile()
+	passphrase, err := privateKeyPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	var provider common.ConfigurationProvider
+	if sessionToken {
+		provider, err = common.ConfigurationProviderForSessionTokenWithProfile(path, profile, passphrase)
+	} else {
+		provider, err = common.ConfigurationProviderFromFileWithProfile(path, profile, passphrase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading OCI config profile %s from %s: %w", profile, path, err)
+	}
+
+	if region := os.Getenv("OCI_REGION"); region != "" {
+		return regionProvider{provider, region}, nil
+	}
+	return provider, nil
+}
+
+// getTenancyID devuelve la tenancy: OCI_TENANCY_ID o la del proveedor de autenticación
+func getTenancyID() string {
+	if tenancy := os.Getenv("OCI_TENANCY_ID"); tenancy != "" {
+		return tenancy
+	}
+	provider, err := createConfigProvider()
+	if err != nil {
+		return ""
+	}
+	tenancy, _ := provider.TenancyOCID()
+	return tenancy
+}
+
+// getRegion devuelve la región: OCI_REGION o la del proveedor de autenticación
+func getRegion() string {
+	if region := os.Getenv("OCI_REGION"); region != "" {
+		return region
+	}
+	provider, err := createConfigProvider()
+	if err != nil {
+		return ""
+	}
+	region, _ := provider.Region()
+	return region
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// clearOCIEnv limpia todas las variables de autenticación
+func clearOCIEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{
+		"OCI_AUTH_MODE", "OCI_TENANCY_ID", "OCI_USER_ID", "OCI_FINGERPRINT", "OCI_PRIVATE_KEY_PATH",
+		"OCI_REGION", "OCI_CONFIG_FILE", "OCI_CONFIG_PROFILE",
+		"OCI_PRIVATE_KEY_PASSPHRASE", "OCI_PRIVATE_KEY_PASSPHRASE_FILE",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+// writeEncryptedKey genera una clave RSA cifrada con passphrase y la guarda en dir
+func writeEncryptedKey(t *testing.T, dir, passphrase string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// x509.EncryptPEMBlock está obsoleto, pero genera el formato cifrado de OpenSSL que usa "oci setup keys"
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key), []byte(passphrase), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestIsConfiguredAuthModes(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(configPath, []byte("[DEFAULT]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"modo desconocido", map[string]string{"OCI_AUTH_MODE": "magic"}, false},
+		{"instance principal no necesita variables", map[string]string{"OCI_AUTH_MODE": "instance_principal"}, true},
+		{"config file existente", map[string]string{"OCI_AUTH_MODE": "config_file", "OCI_CONFIG_FILE": configPath}, true},
+		{"config file inexistente", map[string]string{"OCI_AUTH_MODE": "config_file", "OCI_CONFIG_FILE": filepath.Join(dir, "missing")}, false},
+		{"security token usa el mismo fichero", map[string]string{"OCI_AUTH_MODE": "SECURITY_TOKEN", "OCI_CONFIG_FILE": configPath}, true},
+		{"api key sin variables", map[string]string{"OCI_AUTH_MODE": "api_key"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearOCIEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if got := isConfigured(); got != tt.want {
+				t.Errorf("isConfigured() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAPIKeyProviderPassphrase verifica que las claves cifradas se abren con la passphrase
+// de la variable o del fichero
+func TestAPIKeyProviderPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := writeEncryptedKey(t, dir, "s3cret")
+	passphraseFile := filepath.Join(dir, "passphrase")
+	if err := os.WriteFile(passphraseFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{"passphrase en variable", map[string]string{"OCI_PRIVATE_KEY_PASSPHRASE": "s3cret"}, false},
+		{"passphrase en fichero", map[string]string{"OCI_PRIVATE_KEY_PASSPHRASE_FILE": passphraseFile}, false},
+		{"passphrase incorrecta", map[string]string{"OCI_PRIVATE_KEY_PASSPHRASE": "wrong"}, true},
+		{"sin passphrase", map[string]string{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearOCIEnv(t)
+			setTestOCIEnv(t)
+			t.Setenv("OCI_PRIVATE_KEY_PATH", keyPath)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			provider, err := createConfigProvider()
+			if err != nil {
+				t.Fatalf("createConfigProvider() error = %v", err)
+			}
+			_, err = provider.PrivateRSAKey()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PrivateRSAKey() error = %v; wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestConfigFileProvider verifica la carga de un perfil y la región de OCI_REGION
+func TestConfigFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := writeEncryptedKey(t, dir, "s3cret")
+	configPath := filepath.Join(dir, "config")
+	config := "[DEFAULT]\nuser=ocid1.user.default\nfingerprint=aa\ntenancy=ocid1.tenancy.default\nregion=eu-madrid-1\nkey_file=" + keyPath + "\n" +
+		"[LAB]\nuser=ocid1.user.lab\nfingerprint=bb\ntenancy=ocid1.tenancy.lab\nregion=eu-frankfurt-1\nkey_file=" + keyPath + "\n"
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	clearOCIEnv(t)
+	t.Setenv("OCI_AUTH_MODE", "config_file")
+	t.Setenv("OCI_CONFIG_FILE", configPath)
+	t.Setenv("OCI_CONFIG_PROFILE", "LAB")
+	t.Setenv("OCI_PRIVATE_KEY_PASSPHRASE", "s3cret")
+
+	if got := getTenancyID(); got != "ocid1.tenancy.lab" {
+		t.Errorf("getTenancyID() = %q; want ocid1.tenancy.lab", got)
+	}
+	if got := getRegion(); got != "eu-frankfurt-1" {
+		t.Errorf("getRegion() = %q; want eu-frankfurt-1", got)
+	}
+
+	t.Setenv("OCI_REGION", "us-ashburn-1")
+	if got := getRegion(); got != "us-ashburn-1" {
+		t.Errorf("getRegion() with OCI_REGION = %q; want us-ashburn-1", got)
+	}
+
+	provider, err := createConfigProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.PrivateRSAKey(); err != nil {
+		t.Errorf("PrivateRSAKey() error = %v", err)
+	}
+}
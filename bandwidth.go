@@ -0,0 +1,74 @@
+// Package main - Este archivo mide el tráfico de salida (egress) del mes con OCI Monitoring
+// Superar los 10 TB de salida al mes es de las formas más fáciles de acabar pagando
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// bytesPerTB usa unidades binarias, como el resto de tamaños del watcher
+const bytesPerTB = 1024 * 1024 * 1024 * 1024
+
+// egressQuery es una métrica de Monitoring que cuenta como tráfico de salida
+type egressQuery struct {
+	namespace string
+	query     string
+	lb        bool // true si son bytes de load balancers
+}
+
+// egressQueries suman la salida de todas las VNICs (instancias) y de los load balancers
+// El resultado es una cota superior del egress facturable: Monitoring no distingue el destino,
+// así que el tráfico dentro de la VCN también cuenta, y lo que sale por un load balancer se
+// cuenta dos veces (de la VNIC del backend al LB y del LB al cliente)
+var egressQueries = []egressQuery{
+	{namespace: "oci_vnic", query: "VnicToNetworkBytes[1d].sum()"},
+	{namespace: "oci_lbaas", query: "BytesSent[1d].sum()", lb: true},
+}
+
+// getBandwidthUsage obtiene la salida del mes en curso de un compartimento
+// now se recibe como parámetro para que la proyección sea reproducible en los tests
+func getBandwidthUsage(ctx context.Context, backend ociBackend, compartmentID string, now time.Time) (BandwidthUsage, error) {
+	usage := BandwidthUsage{}
+	limitTB := float64(currentLimits().Bandwidth.EgressTBPerMonth)
+
+	client, err := backend.Monitoring()
+	if err != nil {
+		usage.Error = err.Error()
+		return usage, err
+	}
+
+	for _, q := range egressQueries {
+		series, err := monthToDateSeries(ctx, client, compartmentID, q.namespace, q.query, now)
+		if err != nil {
+			usage.Error = err.Error()
+			return usage, err
+		}
+
+		// Una serie por VNIC o load balancer: se suman todos sus puntos diarios
+		var bytes float64
+		for _, s := range series {
+			bytes += sumDatapoints(s)
+		}
+		if q.lb {
+			usage.LoadBalancerBytes += bytes
+		} else {
+			usage.VnicBytes += bytes
+		}
+	}
+
+	egressTB := (usage.VnicBytes + usage.LoadBalancerBytes) / bytesPerTB
+	projectedTB := projectToMonthEnd(egressTB, now)
+	usage.EgressTB = UsageMetric{
+		Used:       egressTB,
+		Limit:      limitTB,
+		Percentage: int((egressTB / limitTB) * 100),
+	}
+	usage.ProjectedTB = UsageMetric{
+		Used:       projectedTB,
+		Limit:      limitTB,
+		Percentage: int((projectedTB / limitTB) * 100),
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGetBandwidthUsage(t *testing.T) {
+	// 5 TB en los primeros 5 días de octubre: 31 TB al final del mes
+	now := time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC)
+	backend := newScenarioBackend(loadFixture(t, "over_limits.json"))
+
+	usage, err := getBandwidthUsage(context.Background(), backend, testCompartment, now)
+	if err != nil {
+		t.Fatalf("getBandwidthUsage() error = %v", err)
+	}
+	if usage.VnicBytes != 4*bytesPerTB || usage.LoadBalancerBytes != bytesPerTB {
+		t.Errorf("VnicBytes/LoadBalancerBytes = %v/%v; want 4 TB/1 TB", usage.VnicBytes, usage.LoadBalancerBytes)
+	}
+	if usage.EgressTB.Used != 5 || usage.EgressTB.Limit != 10 || usage.EgressTB.Percentage != 50 {
+		t.Errorf("EgressTB = %+v; want 5 of 10 TB", usage.EgressTB)
+	}
+	if math.Abs(usage.ProjectedTB.Used-31) > 1e-9 || usage.ProjectedTB.Percentage != 310 {
+		t.Errorf("ProjectedTB = %+v; want 31 TB (310%%)", usage.ProjectedTB)
+	}
+
+	// La proyección por encima del límite avisa, pero no es CRITICAL por sí sola
+	eval := evaluateUsage(&AllUsage{Bandwidth: usage})
+	if eval.Status != SeverityWarning {
+		t.Errorf("status = %s; want %s (%+v)", eval.Status, SeverityWarning, eval.Findings)
+	}
+}
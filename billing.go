@@ -0,0 +1,115 @@
+// Package main - Este archivo clasifica cada instancia, volumen y load balancer según su
+// coste: siempre gratis, gratis con condiciones (dentro de la asignación Always Free) o de pago
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Clases de facturación de un recurso
+const (
+	BillingAlwaysFree  = "ALWAYS_FREE"          // gratis sea cual sea su configuración
+	BillingConditional = "FREE_WITH_CONDITIONS" // gratis mientras el total no pase la asignación
+	BillingBillable    = "BILLABLE"             // se factura
+)
+
+const (
+	// freeVpusPerGB es el rendimiento máximo de volumen incluido en Always Free (Balanced)
+	freeVpusPerGB = 10
+	// freeLoadBalancerShape es la shape fija antigua equivalente al LB flexible de 10 Mbps
+	freeLoadBalancerShape = "10Mbps-Micro"
+)
+
+// classifyInstance clasifica una instancia por su shape
+// Solo VM.Standard.A1.Flex y VM.Standard.E2.1.Micro son Always Free: una bare metal A1,
+// una E4 o una GPU se facturan aunque el nombre contenga "A1" o "Micro"
+func classifyInstance(shape string) (billing, reason string) {
+	switch shape {
+	case microShape:
+		return BillingAlwaysFree, ""
+	case defaultLaunchShape:
+		return BillingConditional, "free up to the Always Free ARM OCPUs and memory of the tenancy"
+	default:
+		return BillingBillable, fmt.Sprintf("shape %s is not Always Free", shape)
+	}
+}
+
+// instanceArch devuelve la asignación Always Free contra la que cuenta una instancia:
+// "arm" (VM.Standard.A1.Flex), "amd" (VM.Standard.E2.1.Micro) u "other"
+// Sigue a classifyInstance: una bare metal A1 es de pago y no gasta la asignación ARM
+func instanceArch(shape string) string {
+	switch shape {
+	case defaultLaunchShape:
+		return "arm"
+	case microShape:
+		return "amd"
+	default:
+		return "other"
+	}
+}
+
+// classifyVolume clasifica un boot o block volume por su rendimiento (VPUs por GB)
+// El auto-tune puede subir los VPUs por encima de lo configurado: cuenta el mayor de los dos
+func classifyVolume(vpusPerGB, autoTunedVpusPerGB *int64) (billing, reason string, effective *int64) {
+	effective = vpusPerGB
+	if autoTunedVpusPerGB != nil && (effective == nil || *autoTunedVpusPerGB > *effective) {
+		effective = autoTunedVpusPerGB
+	}
+	if effective != nil && *effective > freeVpusPerGB {
+		return BillingBillable, fmt.Sprintf("%d VPUs/GB, Always Free covers up to %d", *effective, freeVpusPerGB), effective
+	}
+	return BillingConditional, "free within the Always Free block storage total", effective
+}
+
+// classifyLoadBalancer clasifica un load balancer por su shape y ancho de banda
+// El LB flexible solo es gratis si su máximo no pasa de limitMbps (10 Mbps)
+func classifyLoadBalancer(shape string, maxBandwidthMbps *int, limitMbps int) (billing, reason string) {
+	switch {
+	case strings.EqualFold(shape, "flexible"):
+		if maxBandwidthMbps == nil {
+			return BillingConditional, "flexible shape without bandwidth details"
+		}
+		if *maxBandwidthMbps > limitMbps {
+			return BillingBillable, fmt.Sprintf("maximum bandwidth %d Mbps, Always Free covers up to %d Mbps", *maxBandwidthMbps, limitMbps)
+		}
+		return BillingConditional, fmt.Sprintf("free for one load balancer up to %d Mbps", limitMbps)
+	case shape == freeLoadBalancerShape:
+		return BillingConditional, fmt.Sprintf("free for one load balancer up to %d Mbps", limitMbps)
+	default:
+		return BillingBillable, fmt.Sprintf("shape %s is not Always Free", shape)
+	}
+}
+
+// billableFindings genera un hallazgo CRITICAL por cada recurso de pago, con su OCID
+func billableFindings(usage *AllUsage) []Finding {
+	var findings []Finding
+	if usage == nil {
+		return findings
+	}
+	add := func(kind, id, name, reason string) {
+		findings = append(findings, Finding{
+			Resource:    "billing." + id,
+			Name:        fmt.Sprintf("Billable %s %s", kind, name),
+			Severity:    SeverityCritical,
+			ResourceIDs: []string{id},
+			Message:     fmt.Sprintf("%s %s (%s) is billed: %s", kind, name, id, reason),
+		})
+	}
+	for _, instance := range usage.Compute.Instances {
+		if instance.Billing == BillingBillable {
+			add("instance", instance.ID, instance.Name, instance.BillingReason)
+		}
+	}
+	for _, volume := range usage.BlockStorage.Volumes {
+		if volume.Billing == BillingBillable {
+			add(volume.Type+" volume", volume.ID, volume.Name, volume.BillingReason)
+		}
+	}
+	for _, lb := range usage.LoadBalancer.LoadBalancers {
+		if lb.Billing == BillingBillable {
+			add("load balancer", lb.ID, lb.Name, lb.BillingReason)
+		}
+	}
+	return findings
+}
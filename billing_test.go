@@ -0,0 +1,121 @@
+package main
+
+import "testing"
+
+func TestClassifyInstance(t *testing.T) {
+	tests := []struct {
+		shape    string
+		want     string
+		wantArch string
+	}{
+		{shape: "VM.Standard.A1.Flex", want: BillingConditional, wantArch: "arm"},
+		{shape: "VM.Standard.E2.1.Micro", want: BillingAlwaysFree, wantArch: "amd"},
+		{shape: "BM.Standard.A1.160", want: BillingBillable, wantArch: "other"},
+		{shape: "VM.Standard.E4.Flex", want: BillingBillable, wantArch: "other"},
+		{shape: "VM.GPU.A10.1", want: BillingBillable, wantArch: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shape, func(t *testing.T) {
+			got, reason := classifyInstance(tt.shape)
+			if got != tt.want {
+				t.Errorf("classifyInstance(%q) = %s; want %s", tt.shape, got, tt.want)
+			}
+			if got == BillingBillable && reason == "" {
+				t.Error("billable instance without reason")
+			}
+			if arch := instanceArch(tt.shape); arch != tt.wantArch {
+				t.Errorf("instanceArch(%q) = %s; want %s", tt.shape, arch, tt.wantArch)
+			}
+		})
+	}
+}
+
+func TestClassifyVolume(t *testing.T) {
+	vpus := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name          string
+		vpus, tuned   *int64
+		want          string
+		wantEffective *int64
+	}{
+		{name: "sin VPUs", want: BillingConditional},
+		{name: "Balanced", vpus: vpus(10), want: BillingConditional, wantEffective: vpus(10)},
+		{name: "Lower Cost", vpus: vpus(0), want: BillingConditional, wantEffective: vpus(0)},
+		{name: "Higher Performance", vpus: vpus(20), want: BillingBillable, wantEffective: vpus(20)},
+		{name: "auto-tune por encima", vpus: vpus(10), tuned: vpus(30), want: BillingBillable, wantEffective: vpus(30)},
+		{name: "auto-tune por debajo", vpus: vpus(10), tuned: vpus(0), want: BillingConditional, wantEffective: vpus(10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, effective := classifyVolume(tt.vpus, tt.tuned)
+			if got != tt.want {
+				t.Errorf("billing = %s; want %s", got, tt.want)
+			}
+			if (effective == nil) != (tt.wantEffective == nil) || (effective != nil && *effective != *tt.wantEffective) {
+				t.Errorf("effective = %v; want %v", effective, tt.wantEffective)
+			}
+		})
+	}
+}
+
+func TestClassifyLoadBalancer(t *testing.T) {
+	mbps := func(v int) *int { return &v }
+
+	tests := []struct {
+		name  string
+		shape string
+		max   *int
+		want  string
+	}{
+		{name: "flexible 10 Mbps", shape: "flexible", max: mbps(10), want: BillingConditional},
+		{name: "flexible 100 Mbps", shape: "flexible", max: mbps(100), want: BillingBillable},
+		{name: "flexible sin detalles", shape: "flexible", want: BillingConditional},
+		{name: "shape fija Micro", shape: "10Mbps-Micro", want: BillingConditional},
+		{name: "shape fija 100Mbps", shape: "100Mbps", want: BillingBillable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := classifyLoadBalancer(tt.shape, tt.max, 10); got != tt.want {
+				t.Errorf("classifyLoadBalancer(%q) = %s; want %s", tt.shape, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBillableFindings verifica que cada recurso de pago eleva a CRITICAL con su OCID
+func TestBillableFindings(t *testing.T) {
+	usage := &AllUsage{}
+	usage.Compute.Instances = []InstanceInfo{
+		{ID: "ocid1.instance.a1", Name: "arm-1", Arch: "arm", Billing: BillingConditional},
+		{ID: "ocid1.instance.e4", Name: "e4-1", Arch: "other", Billing: BillingBillable, BillingReason: "shape VM.Standard.E4.Flex is not Always Free"},
+	}
+	usage.BlockStorage.Volumes = []VolumeInfo{
+		{ID: "ocid1.bootvolume.fast", Name: "boot-1", Type: "boot", Billing: BillingBillable, BillingReason: "20 VPUs/GB"},
+	}
+	usage.LoadBalancer.LoadBalancers = []LoadBalancerInfo{
+		{ID: "ocid1.loadbalancer.free", Name: "lb-1", Shape: "flexible", Billing: BillingConditional},
+	}
+
+	eval := evaluateUsage(usage)
+	if eval.Status != SeverityCritical {
+		t.Errorf("Status = %s; want %s", eval.Status, SeverityCritical)
+	}
+	got := map[string]bool{}
+	for _, finding := range eval.Findings {
+		if finding.Severity == SeverityCritical && len(finding.ResourceIDs) == 1 {
+			got[finding.ResourceIDs[0]] = true
+		}
+	}
+	for _, id := range []string{"ocid1.instance.e4", "ocid1.bootvolume.fast"} {
+		if !got[id] {
+			t.Errorf("missing CRITICAL finding for %s", id)
+		}
+	}
+	if len(got) != 2 {
+		t.Errorf("got CRITICAL findings for %v; want only the 2 billable resources", got)
+	}
+}
@@ -0,0 +1,379 @@
+// Package main - Este archivo consulta la capacidad A1 disponible en cada dominio de
+// disponibilidad (CreateComputeCapacityReport) y guarda los resultados en el histórico
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Estados de capacidad que devuelve OCI, más el de error propio
+const (
+	CapacityAvailable         = string(core.CapacityReportShapeAvailabilityAvailabilityStatusAvailable)
+	CapacityOutOfHostCapacity = string(core.CapacityReportShapeAvailabilityAvailabilityStatusOutOfHostCapacity)
+	CapacityError             = "ERROR" // la consulta de ese AD falló
+)
+
+// CapacityReport es el resultado de consultar la capacidad de una shape en todos los ADs
+type CapacityReport struct {
+	Shape     string           `json:"shape"`
+	OCPUs     float64          `json:"ocpus"`
+	MemoryGB  float64          `json:"memoryGB"`
+	Timestamp string           `json:"timestamp"`
+	Results   []CapacityResult `json:"results"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// CapacityResult es la capacidad de un dominio de disponibilidad
+type CapacityResult struct {
+	AvailabilityDomain string `json:"availabilityDomain"`
+	Status             string `json:"status"`                   // AVAILABLE, OUT_OF_HOST_CAPACITY, HARDWARE_NOT_SUPPORTED o ERROR
+	AvailableCount     int64  `json:"availableCount,omitempty"` // instancias de ese tamaño que caben
+	Error              string `json:"error,omitempty"`
+}
+
+// Available indica si algún AD tiene capacidad
+func (r CapacityReport) Available() bool {
+	for _, result := range r.Results {
+		if result.Status == CapacityAvailable {
+			return true
+		}
+	}
+	return false
+}
+
+// CapacityAvailability resume los informes recientes de un AD
+type CapacityAvailability struct {
+	AvailabilityDomain string `json:"availabilityDomain"`
+	Reports            int    `json:"reports"`
+	AvailableReports   int    `json:"availableReports"`
+	LastStatus         string `json:"lastStatus"`
+	LastChecked        string `json:"lastChecked"`
+	LastAvailable      string `json:"lastAvailable,omitempty"` // vacío si no hubo capacidad en la ventana
+}
+
+// CapacityResponse es la respuesta del endpoint /capacity
+type CapacityResponse struct {
+	Report    CapacityReport         `json:"report"`
+	Window    string                 `json:"window"`
+	Recent    []CapacityAvailability `json:"recent"`
+	Timestamp string                 `json:"timestamp"`
+}
+
+// getCapacityReport pide a OCI un informe de capacidad para la shape en cada AD
+// Un AD que falla no invalida el resto: queda con estado ERROR
+func getCapacityReport(ctx context.Context, backend ociBackend, tenancyID, compartmentID, shape string, ocpus, memoryGB float64, now time.Time) (CapacityReport, error) {
+	report := CapacityReport{
+		Shape:     shape,
+		OCPUs:     ocpus,
+		MemoryGB:  memoryGB,
+		Timestamp: now.UTC().Format(time.RFC3339),
+		Results:   []CapacityResult{},
+	}
+
+	identityClient, err := backend.Identity()
+	if err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+	compute, err := backend.Compute()
+	if err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+
+	ads, err := identityClient.ListAvailabilityDomains(ctx, identity.ListAvailabilityDomainsRequest{
+		CompartmentId: common.String(tenancyID),
+	})
+	if err != nil {
+		err = ociError("identity", "ListAvailabilityDomains", err)
+		report.Error = err.Error()
+		return report, err
+	}
+
+	for _, ad := range ads.Items {
+		if ad.Name == nil {
+			continue
+		}
+		result := CapacityResult{AvailabilityDomain: *ad.Name}
+		response, err := compute.CreateComputeCapacityReport(ctx, core.CreateComputeCapacityReportRequest{
+			CreateComputeCapacityReportDetails: core.CreateComputeCapacityReportDetails{
+				CompartmentId:      common.String(compartmentID),
+				AvailabilityDomain: ad.Name,
+				ShapeAvailabilities: []core.CreateCapacityReportShapeAvailabilityDetails{{
+					InstanceShape: common.String(shape),
+					InstanceShapeConfig: &core.CapacityReportInstanceShapeConfig{
+						Ocpus:       common.Float32(float32(ocpus)),
+						MemoryInGBs: common.Float32(float32(memoryGB)),
+					},
+				}},
+			},
+		})
+		switch {
+		case err != nil:
+			result.Status = CapacityError
+			result.Error = ociError("core", "CreateComputeCapacityReport", err).Error()
+		case len(response.ShapeAvailabilities) == 0:
+			result.Status = CapacityError
+			result.Error = "empty capacity report"
+		default:
+			availability := response.ShapeAvailabilities[0]
+			result.Status = string(availability.AvailabilityStatus)
+			if availability.AvailableCount != nil {
+				result.AvailableCount = *availability.AvailableCount
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// RecordCapacity guarda un informe de capacidad
+// Los informes ocupan poco: se conservan tanto como los rollups diarios
+func (h *HistoryStore) RecordCapacity(report CapacityReport, at time.Time) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyCapacityBucket)
+		if err := bucket.Put(historyKey(at), data); err != nil {
+			return err
+		}
+		return deleteHistoryBefore(bucket, at.Add(-h.dailyRetention))
+	})
+	if err != nil {
+		return fmt.Errorf("error writing capacity history: %w", err)
+	}
+	return nil
+}
+
+// CapacityHistory devuelve los informes guardados desde from, del más antiguo al más reciente
+func (h *HistoryStore) CapacityHistory(from time.Time) ([]CapacityReport, error) {
+	var reports []CapacityReport
+	err := h.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(historyCapacityBucket).Cursor()
+		for k, v := c.Seek(historyKey(from)); k != nil; k, v = c.Next() {
+			var report CapacityReport
+			if err := json.Unmarshal(v, &report); err != nil {
+				return err
+			}
+			reports = append(reports, report)
+		}
+		return nil
+	})
+	return reports, err
+}
+
+// summarizeCapacity resume por AD cuántas veces hubo capacidad y cuándo fue la última
+func summarizeCapacity(reports []CapacityReport) []CapacityAvailability {
+	byAD := map[string]*CapacityAvailability{}
+	for _, report := range reports {
+		for _, result := range report.Results {
+			summary, ok := byAD[result.AvailabilityDomain]
+			if !ok {
+				summary = &CapacityAvailability{AvailabilityDomain: result.AvailabilityDomain}
+				byAD[result.AvailabilityDomain] = summary
+			}
+			// Los informes llegan ordenados: el último visto es el más reciente
+			summary.Reports++
+			summary.LastStatus = result.Status
+			summary.LastChecked = report.Timestamp
+			if result.Status == CapacityAvailable {
+				summary.AvailableReports++
+				summary.LastAvailable = report.Timestamp
+			}
+		}
+	}
+
+	summaries := make([]CapacityAvailability, 0, len(byAD))
+	for _, summary := range byAD {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].AvailabilityDomain < summaries[j].AvailabilityDomain
+	})
+	return summaries
+}
+
+// capacityRequest son los parámetros de un informe: la shape A1 y el tamaño pedido
+// Por defecto se consulta la Free Tier completa (CAPACITY_OCPUS/CAPACITY_MEMORY_GB)
+type capacityRequest struct {
+	OCPUs    float64
+	MemoryGB float64
+}
+
+// defaultCapacityRequest lee el tamaño por defecto del entorno
+func defaultCapacityRequest() capacityRequest {
+	return capacityRequest{
+		OCPUs:    getEnvFloat("CAPACITY_OCPUS", 4),
+		MemoryGB: getEnvFloat("CAPACITY_MEMORY_GB", 24),
+	}
+}
+
+// runCapacityReport crea un informe, lo guarda en el histórico (si hay) y lo resume
+func runCapacityReport(ctx context.Context, req capacityRequest, window time.Duration) (CapacityResponse, error) {
+	provider, err := createConfigProvider()
+	if err != nil {
+		return CapacityResponse{}, err
+	}
+	now := time.Now().UTC()
+	report, err := getCapacityReport(ctx, newOCIBackend(provider), getTenancyID(), getCompartmentID(),
+		defaultLaunchShape, req.OCPUs, req.MemoryGB, now)
+	if err != nil {
+		return CapacityResponse{}, err
+	}
+
+	// Sin histórico, el resumen solo incluye el informe actual
+	reports := []CapacityReport{report}
+	if history != nil {
+		if err := history.RecordCapacity(report, now); err != nil {
+			logger.Error().Err(err).Msg("Error recording capacity report")
+		} else if stored, err := history.CapacityHistory(now.Add(-window)); err != nil {
+			logger.Error().Err(err).Msg("Error reading capacity history")
+		} else {
+			reports = stored
+		}
+	}
+
+	return CapacityResponse{
+		Report:    report,
+		Window:    window.String(),
+		Recent:    summarizeCapacity(reports),
+		Timestamp: now.Format(time.RFC3339),
+	}, nil
+}
+
+// capacityHandler maneja GET /capacity?ocpus=4&memoryGB=24&window=24h
+// Cada llamada crea un informe nuevo en OCI
+func capacityHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isConfigured() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "OCI not configured"})
+		return
+	}
+
+	query := r.URL.Query()
+	req := defaultCapacityRequest()
+	for name, target := range map[string]*float64{"ocpus": &req.OCPUs, "memoryGB": &req.MemoryGB} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil || n <= 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid %s %q", name, value)})
+				return
+			}
+			*target = n
+		}
+	}
+	window := 24 * time.Hour
+	if value := query.Get("window"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid window %q", value)})
+			return
+		}
+		window = d
+	}
+
+	response, err := runCapacityReport(r.Context(), req, window)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// runCapacityCommand muestra la capacidad A1 por AD
+// Sale con 0 si algún AD tiene capacidad y con 1 si ninguno la tiene
+func runCapacityCommand(args []string, stdout io.Writer) int {
+	defaults := defaultCapacityRequest()
+	flags := flag.NewFlagSet("capacity", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	ocpus := flags.Float64("ocpus", defaults.OCPUs, "OCPUs of the instance to check")
+	memoryGB := flags.Float64("memory", defaults.MemoryGB, "memory (GB) of the instance to check")
+	window := flags.Duration("window", 24*time.Hour, "summarize the reports recorded in this window")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUnknown
+	}
+	if !isConfigured() {
+		fmt.Fprintln(stdout, "NOT_CONFIGURED: OCI credentials not configured")
+		return exitUnknown
+	}
+
+	// El servidor puede tener abierto el histórico: bbolt lo bloquea y aquí se sigue sin él
+	store, err := openHistoryStore(getEnv("HISTORY_PATH", "history.db"),
+		getEnvDuration("HISTORY_RAW_RETENTION", 48*time.Hour),
+		getEnvDuration("HISTORY_HOURLY_RETENTION", 30*24*time.Hour),
+		getEnvDuration("HISTORY_DAILY_RETENTION", 365*24*time.Hour),
+	)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Capacity report will not be recorded in history")
+	} else {
+		history = store
+		defer func() {
+			store.Close()
+			history = nil
+		}()
+	}
+
+	response, err := runCapacityReport(context.Background(), capacityRequest{OCPUs: *ocpus, MemoryGB: *memoryGB}, *window)
+	if err != nil {
+		fmt.Fprintf(stdout, "ERROR: %v\n", err)
+		return exitUnknown
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(response); err != nil {
+			return exitUnknown
+		}
+	} else {
+		printCapacity(stdout, response)
+	}
+	if response.Report.Available() {
+		return exitOK
+	}
+	return exitWarning
+}
+
+// printCapacity escribe el informe en formato legible
+func printCapacity(w io.Writer, response CapacityResponse) {
+	report := response.Report
+	fmt.Fprintf(w, "%s %g OCPUs / %g GB\n", report.Shape, report.OCPUs, report.MemoryGB)
+	for _, result := range report.Results {
+		line := fmt.Sprintf("  %-32s %s", result.AvailabilityDomain, result.Status)
+		if result.AvailableCount > 0 {
+			line += fmt.Sprintf(" (%d)", result.AvailableCount)
+		}
+		if result.Error != "" {
+			line += ": " + result.Error
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "Last %s:\n", response.Window)
+	for _, summary := range response.Recent {
+		last := "never"
+		if summary.LastAvailable != "" {
+			last = summary.LastAvailable
+		}
+		fmt.Fprintf(w, "  %-32s available %d/%d, last available %s\n",
+			summary.AvailabilityDomain, summary.AvailableReports, summary.Reports, last)
+	}
+}
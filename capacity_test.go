@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+func TestGetCapacityReport(t *testing.T) {
+	scenario := launchScenario()
+	scenario.Capacity = map[string]string{"Uocm:EU-MADRID-1-AD-2": CapacityAvailable}
+	scenario.Errors["CreateComputeCapacityReport:Uocm:EU-MADRID-1-AD-1"] = scenarioError{Status: http.StatusTooManyRequests, Code: "TooManyRequests", Message: "slow down"}
+	scenario.AvailabilityDomains = append(scenario.AvailabilityDomains, identity.AvailabilityDomain{Name: common.String("Uocm:EU-MADRID-1-AD-3")})
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	report, err := getCapacityReport(context.Background(), newScenarioBackend(scenario), "ocid1.tenancy.oc1..test", testCompartment, defaultLaunchShape, 4, 24, now)
+	if err != nil {
+		t.Fatalf("getCapacityReport: %v", err)
+	}
+	want := map[string]string{
+		"Uocm:EU-MADRID-1-AD-1": CapacityError,
+		"Uocm:EU-MADRID-1-AD-2": CapacityAvailable,
+		"Uocm:EU-MADRID-1-AD-3": CapacityOutOfHostCapacity,
+	}
+	if len(report.Results) != len(want) {
+		t.Fatalf("results = %+v", report.Results)
+	}
+	for _, result := range report.Results {
+		if result.Status != want[result.AvailabilityDomain] {
+			t.Errorf("%s = %s; want %s", result.AvailabilityDomain, result.Status, want[result.AvailabilityDomain])
+		}
+	}
+	if !report.Available() {
+		t.Error("Available() = false; AD-2 has capacity")
+	}
+}
+
+func TestCapacityHistory(t *testing.T) {
+	store, err := openHistoryStore(filepath.Join(t.TempDir(), "history.db"), time.Hour, 24*time.Hour, 48*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	start := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	report := func(at time.Time, ad1, ad2 string) CapacityReport {
+		return CapacityReport{
+			Timestamp: at.Format(time.RFC3339),
+			Results: []CapacityResult{
+				{AvailabilityDomain: "AD-1", Status: ad1},
+				{AvailabilityDomain: "AD-2", Status: ad2},
+			},
+		}
+	}
+	reports := []CapacityReport{
+		report(start, CapacityAvailable, CapacityOutOfHostCapacity),
+		report(start.Add(time.Hour), CapacityOutOfHostCapacity, CapacityOutOfHostCapacity),
+		report(start.Add(2*time.Hour), CapacityOutOfHostCapacity, CapacityError),
+	}
+	for i, r := range reports {
+		if err := store.RecordCapacity(r, start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stored, err := store.CapacityHistory(start)
+	if err != nil || len(stored) != 3 {
+		t.Fatalf("CapacityHistory = %d reports, %v", len(stored), err)
+	}
+	summary := summarizeCapacity(stored)
+	if len(summary) != 2 {
+		t.Fatalf("summary = %+v", summary)
+	}
+	ad1, ad2 := summary[0], summary[1]
+	if ad1.Reports != 3 || ad1.AvailableReports != 1 || ad1.LastAvailable != start.Format(time.RFC3339) || ad1.LastStatus != CapacityOutOfHostCapacity {
+		t.Errorf("AD-1 = %+v", ad1)
+	}
+	if ad2.AvailableReports != 0 || ad2.LastAvailable != "" || ad2.LastStatus != CapacityError {
+		t.Errorf("AD-2 = %+v", ad2)
+	}
+
+	// Solo la ventana pedida
+	if recent, _ := store.CapacityHistory(start.Add(90 * time.Minute)); len(recent) != 1 {
+		t.Errorf("reports in the last window = %d; want 1", len(recent))
+	}
+
+	// La retención diaria (48h) borra los informes viejos al guardar uno nuevo
+	if err := store.RecordCapacity(reports[0], start.Add(72*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := store.CapacityHistory(start); len(all) != 1 {
+		t.Errorf("reports after retention = %d; want 1", len(all))
+	}
+}
@@ -0,0 +1,120 @@
+// Package main - Este archivo contiene los subcomandos de línea de comandos
+// Sin argumentos el binario arranca el servidor HTTP; con un subcomando ejecuta y sale
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// Códigos de salida del CLI, compatibles con los checks de Nagios/Icinga
+const (
+	exitOK       = 0
+	exitWarning  = 1
+	exitCritical = 2
+	exitUnknown  = 3
+)
+
+// runCLI ejecuta un subcomando y devuelve el código de salida
+func runCLI(args []string, stdout io.Writer) int {
+	switch args[0] {
+	case "check":
+		return runCheckCommand(args[1:], stdout)
+	case "stub":
+		return runStubCommand(args[1:], stdout)
+	case "capacity":
+		return runCapacityCommand(args[1:], stdout)
+	case "launch":
+		return runLaunchCommand(args[1:], stdout)
+	case "help", "-h", "--help":
+		printCLIUsage(stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(stdout, "unknown command %q\n\n", args[0])
+		printCLIUsage(stdout)
+		return exitUnknown
+	}
+}
+
+// printCLIUsage muestra la ayuda del CLI
+func printCLIUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: watcher [command]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Without a command the HTTP server is started.")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  check [-json]   Collect usage once, print the evaluation and exit with 0 (OK/ATTENTION), 1 (WARNING), 2 (CRITICAL) or 3 (DEGRADED/UNKNOWN or error)")
+	fmt.Fprintln(w, "  capacity [-ocpus N] [-memory GB] [-json]  Report A1 capacity per availability domain; exit 0 if any AD has capacity, 1 if none")
+	fmt.Fprintln(w, "  launch -template F  Retry LaunchInstance across all availability/fault domains until an A1 instance is created")
+	fmt.Fprintln(w, "  stub -scenario F  Serve a fake OCI API from a scenario file (use with OCI_ENDPOINT_OVERRIDE)")
+}
+
+// runCheckCommand recolecta el uso una vez y muestra la evaluación
+func runCheckCommand(args []string, stdout io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	asJSON := flags.Bool("json", false, "print the evaluation as JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUnknown
+	}
+
+	if !isConfigured() {
+		fmt.Fprintln(stdout, "NOT_CONFIGURED: OCI credentials not configured")
+		return exitUnknown
+	}
+
+	usage, err := getOCIUsage(context.Background())
+	if err != nil {
+		fmt.Fprintf(stdout, "ERROR: %v\n", err)
+		return exitUnknown
+	}
+
+	eval := evaluateUsage(usage)
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(eval); err != nil {
+			return exitUnknown
+		}
+	} else {
+		printEvaluation(stdout, eval)
+	}
+
+	return evaluationExitCode(eval)
+}
+
+// printEvaluation escribe la evaluación en formato legible
+func printEvaluation(w io.Writer, eval Evaluation) {
+	fmt.Fprintf(w, "%s %s (max %d%%)\n", statusEmoji(eval.Status), eval.Status, eval.MaxPercentage)
+	for _, f := range eval.Findings {
+		fmt.Fprintf(w, "  [%s] %s\n", f.Severity, f.Message)
+		for _, id := range f.ResourceIDs {
+			fmt.Fprintf(w, "      %s\n", id)
+		}
+	}
+	for _, e := range eval.Errors {
+		fmt.Fprintf(w, "  [ERROR] %s %s %s: %s\n", e.Collector, e.Service, e.Operation, e.Message)
+		if e.OpcRequestID != "" {
+			fmt.Fprintf(w, "      opc-request-id %s\n", e.OpcRequestID)
+		}
+	}
+}
+
+// evaluationExitCode traduce el estado a código de salida
+// Con datos incompletos (DEGRADED/UNKNOWN) el check es UNKNOWN, como en Nagios
+func evaluationExitCode(eval Evaluation) int {
+	switch eval.Status {
+	case SeverityCritical:
+		return exitCritical
+	case SeverityDegraded, SeverityUnknown:
+		return exitUnknown
+	case SeverityWarning:
+		return exitWarning
+	default:
+		return exitOK
+	}
+}
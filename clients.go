@@ -0,0 +1,203 @@
+// Package main - Este archivo define las interfaces de los clientes de OCI que usan
+// los colectores, para poder sustituir el SDK por un backend falso en los tests
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/database"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+	"github.com/oracle/oci-go-sdk/v65/limits"
+	"github.com/oracle/oci-go-sdk/v65/loadbalancer"
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+)
+
+// En Go, una interfaz solo declara los métodos que necesitamos: los clientes del SDK
+// la cumplen sin saberlo, y un fake solo tiene que implementar estas pocas llamadas
+
+// computeAPI son las llamadas de core.ComputeClient que usan los colectores, el lanzador
+// y el informe de capacidad
+type computeAPI interface {
+	ListInstances(ctx context.Context, request core.ListInstancesRequest) (core.ListInstancesResponse, error)
+	LaunchInstance(ctx context.Context, request core.LaunchInstanceRequest) (core.LaunchInstanceResponse, error)
+	CreateComputeCapacityReport(ctx context.Context, request core.CreateComputeCapacityReportRequest) (core.CreateComputeCapacityReportResponse, error)
+}
+
+// blockstorageAPI son las llamadas de core.BlockstorageClient
+type blockstorageAPI interface {
+	ListBootVolumes(ctx context.Context, request core.ListBootVolumesRequest) (core.ListBootVolumesResponse, error)
+	ListVolumes(ctx context.Context, request core.ListVolumesRequest) (core.ListVolumesResponse, error)
+}
+
+// virtualNetworkAPI son las llamadas de core.VirtualNetworkClient
+type virtualNetworkAPI interface {
+	ListPublicIps(ctx context.Context, request core.ListPublicIpsRequest) (core.ListPublicIpsResponse, error)
+}
+
+// objectStorageAPI son las llamadas de objectstorage.ObjectStorageClient
+type objectStorageAPI interface {
+	GetNamespace(ctx context.Context, request objectstorage.GetNamespaceRequest) (objectstorage.GetNamespaceResponse, error)
+	ListBuckets(ctx context.Context, request objectstorage.ListBucketsRequest) (objectstorage.ListBucketsResponse, error)
+	GetBucket(ctx context.Context, request objectstorage.GetBucketRequest) (objectstorage.GetBucketResponse, error)
+}
+
+// loadBalancerAPI son las llamadas de loadbalancer.LoadBalancerClient
+type loadBalancerAPI interface {
+	ListLoadBalancers(ctx context.Context, request loadbalancer.ListLoadBalancersRequest) (loadbalancer.ListLoadBalancersResponse, error)
+}
+
+// databaseAPI son las llamadas de database.DatabaseClient
+type databaseAPI interface {
+	ListAutonomousDatabases(ctx context.Context, request database.ListAutonomousDatabasesRequest) (database.ListAutonomousDatabasesResponse, error)
+}
+
+// monitoringAPI son las llamadas de monitoring.MonitoringClient
+type monitoringAPI interface {
+	SummarizeMetricsData(ctx context.Context, request monitoring.SummarizeMetricsDataRequest) (monitoring.SummarizeMetricsDataResponse, error)
+}
+
+// identityAPI son las llamadas de identity.IdentityClient que usan el lanzador, el informe de
+// capacidad y el recorrido de compartimentos y regiones
+type identityAPI interface {
+	ListAvailabilityDomains(ctx context.Context, request identity.ListAvailabilityDomainsRequest) (identity.ListAvailabilityDomainsResponse, error)
+	ListFaultDomains(ctx context.Context, request identity.ListFaultDomainsRequest) (identity.ListFaultDomainsResponse, error)
+	ListCompartments(ctx context.Context, request identity.ListCompartmentsRequest) (identity.ListCompartmentsResponse, error)
+	ListRegionSubscriptions(ctx context.Context, request identity.ListRegionSubscriptionsRequest) (identity.ListRegionSubscriptionsResponse, error)
+}
+
+// limitsAPI son las llamadas de limits.LimitsClient que usa /limits
+type limitsAPI interface {
+	ListLimitValues(ctx context.Context, request limits.ListLimitValuesRequest) (limits.ListLimitValuesResponse, error)
+	GetResourceAvailability(ctx context.Context, request limits.GetResourceAvailabilityRequest) (limits.GetResourceAvailabilityResponse, error)
+}
+
+// ociBackend crea los clientes de cada servicio
+type ociBackend interface {
+	Compute() (computeAPI, error)
+	Blockstorage() (blockstorageAPI, error)
+	VirtualNetwork() (virtualNetworkAPI, error)
+	ObjectStorage() (objectStorageAPI, error)
+	LoadBalancer() (loadBalancerAPI, error)
+	Database() (databaseAPI, error)
+	Monitoring() (monitoringAPI, error)
+	Identity() (identityAPI, error)
+	Limits() (limitsAPI, error)
+}
+
+// newOCIBackend crea el backend de un proveedor de autenticación
+// Es una variable para que los tests puedan sustituirla por un backend falso
+var newOCIBackend = func(provider common.ConfigurationProvider) ociBackend {
+	// El SDK rellena un mapa global la primera vez que se crea un cliente, sin mutex;
+	// los colectores crean sus clientes en paralelo, así que se inicializa antes una sola vez
+	sdkServicesOnce.Do(func() { common.CheckForEnabledServices("") })
+	return sdkBackend{provider: provider, endpoint: ociEndpointOverride()}
+}
+
+// sdkServicesOnce protege la inicialización del mapa global de servicios del SDK
+var sdkServicesOnce sync.Once
+
+// ociEndpointOverride es la URL base que sustituye a los endpoints de OCI (OCI_ENDPOINT_OVERRIDE)
+// Sirve para apuntar el watcher al servidor de pruebas ("watcher stub") sin salir a la red
+func ociEndpointOverride() string {
+	return strings.TrimRight(getEnv("OCI_ENDPOINT_OVERRIDE", ""), "/")
+}
+
+// sdkBackend crea los clientes reales del SDK de OCI
+type sdkBackend struct {
+	provider common.ConfigurationProvider
+	endpoint string // si no está vacío, sustituye al host de todos los clientes
+}
+
+// override apunta el cliente al endpoint configurado
+// Todos los clientes del SDK embeben common.BaseClient, que guarda el host
+func (b sdkBackend) override(client *common.BaseClient) {
+	if b.endpoint != "" {
+		client.Host = b.endpoint
+	}
+}
+
+func (b sdkBackend) Compute() (computeAPI, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(b.provider)
+	if err != nil {
+		return nil, ociError("core", "NewComputeClient", err)
+	}
+	b.override(&client.BaseClient)
+	return client, nil
+}
+
+func (b sdkBackend) Blockstorage() (blockstorageAPI, error) {
+	client, err := core.NewBlockstorageClientWithConfigurationProvider(b.provider)
+	if err != nil {
+		return nil, ociError("core", "NewBlockstorageClient", err)
+	}
+	b.override(&client.BaseClient)
+	return client, nil
+}
+
+func (b sdkBackend) VirtualNetwork() (virtualNetworkAPI, error) {
+	client, err := core.NewVirtualNetworkClientWithConfigurationProvider(b.provider)
+	if err != nil {
+		return nil, ociError("core", "NewVirtualNetworkClient", err)
+	}
+	b.override(&client.BaseClient)
+	return client, nil
+}
+
+func (b sdkBackend) ObjectStorage() (objectStorageAPI, error) {
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(b.provider)
+	if err != nil {
+		return nil, ociError("objectstorage", "NewObjectStorageClient", err)
+	}
+	b.override(&client.BaseClient)
+	return client, nil
+}
+
+func (b sdkBackend) LoadBalancer() (loadBalancerAPI, error) {
+	client, err := loadbalancer.NewLoadBalancerClientWithConfigurationProvider(b.provider)
+	if err != nil {
+		return nil, ociError("loadbalancer", "NewLoadBalancerClient", err)
+	}
+	b.override(&client.BaseClient)
+	return client, nil
+}
+
+func (b sdkBackend) Database() (databaseAPI, error) {
+	client, err := database.NewDatabaseClientWithConfigurationProvider(b.provider)
+	if err != nil {
+		return nil, ociError("database", "NewDatabaseClient", err)
+	}
+	b.override(&client.BaseClient)
+	return client, nil
+}
+
+func (b sdkBackend) Monitoring() (monitoringAPI, error) {
+	client, err := monitoring.NewMonitoringClientWithConfigurationProvider(b.provider)
+	if err != nil {
+		return nil, ociError("monitoring", "NewMonitoringClient", err)
+	}
+	b.override(&client.BaseClient)
+	return client, nil
+}
+
+func (b sdkBackend) Identity() (identityAPI, error) {
+	client, err := identity.NewIdentityClientWithConfigurationProvider(b.provider)
+	if err != nil {
+		return nil, ociError("identity", "NewIdentityClient", err)
+	}
+	b.override(&client.BaseClient)
+	return client, nil
+}
+
+func (b sdkBackend) Limits() (limitsAPI, error) {
+	client, err := limits.NewLimitsClientWithConfigurationProvider(b.provider)
+	if err != nil {
+		return nil, ociError("limits", "NewLimitsClient", err)
+	}
+	b.override(&client.BaseClient)
+	return client, nil
+}
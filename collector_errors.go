@@ -0,0 +1,118 @@
+// Package main - Este archivo convierte los fallos de los colectores en errores
+// estructurados, para no confundir "sin datos" con "uso 0"
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+// CollectorError describe el fallo de un colector en /usage
+type CollectorError struct {
+	Collector    string `json:"collector"`
+	Compartment  string `json:"compartment,omitempty"` // ruta del compartimento en modo recursivo
+	Region       string `json:"region,omitempty"`      // región en modo multi-región
+	Service      string `json:"service,omitempty"`
+	Operation    string `json:"operation,omitempty"`
+	HTTPStatus   int    `json:"httpStatus,omitempty"`
+	Code         string `json:"code,omitempty"`
+	OpcRequestID string `json:"opcRequestId,omitempty"` // necesario si hay que abrir un caso con Oracle
+	Message      string `json:"message"`
+	TimedOut     bool   `json:"timedOut,omitempty"`
+}
+
+// ociCallError recuerda qué servicio y operación de OCI falló
+// En Go, un error que envuelve a otro implementa Unwrap para que errors.As lo encuentre
+type ociCallError struct {
+	Service   string
+	Operation string
+	Err       error
+}
+
+func (e *ociCallError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Service, e.Operation, e.Err)
+}
+
+func (e *ociCallError) Unwrap() error {
+	return e.Err
+}
+
+// ociError envuelve el error de una llamada a OCI con su servicio y operación
+func ociError(service, operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ociCallError{Service: service, Operation: operation, Err: err}
+}
+
+// newCollectorError extrae toda la información útil de un error de colector
+func newCollectorError(collector string, err error) CollectorError {
+	ce := CollectorError{
+		Collector: collector,
+		Message:   err.Error(),
+		TimedOut:  errors.Is(err, context.DeadlineExceeded),
+	}
+
+	var call *ociCallError
+	if errors.As(err, &call) {
+		ce.Service = call.Service
+		ce.Operation = call.Operation
+		err = call.Err
+	}
+
+	// Los errores del servicio traen el código HTTP y el opc-request-id
+	if failure, ok := common.IsServiceError(err); ok {
+		ce.HTTPStatus = failure.GetHTTPStatusCode()
+		ce.Code = failure.GetCode()
+		ce.OpcRequestID = failure.GetOpcRequestID()
+		ce.Message = failure.GetMessage()
+	}
+	if rich, ok := common.IsServiceErrorRichInfo(err); ok {
+		if ce.Service == "" {
+			ce.Service = rich.GetTargetService()
+		}
+		if ce.Operation == "" {
+			ce.Operation = rich.GetOperationName()
+		}
+	}
+
+	return ce
+}
+
+// failedCollectors devuelve los colectores con error de un snapshot
+func failedCollectors(usage *AllUsage) map[string]bool {
+	failed := map[string]bool{}
+	if usage == nil {
+		return failed
+	}
+	for _, e := range usage.Errors {
+		failed[e.Collector] = true
+	}
+	return failed
+}
+
+// reliableUsageMetrics es flattenUsageMetrics sin las métricas de los colectores que
+// fallaron: sus valores a 0 no significan "sin uso" sino "sin datos"
+// En modo multi-región los totales son solo de la región principal: un fallo en otra
+// región no los invalida (aunque el estado siga siendo DEGRADED)
+func reliableUsageMetrics(usage *AllUsage) map[string]UsageMetric {
+	metrics := flattenUsageMetrics(usage)
+	failed := map[string]bool{}
+	if usage != nil {
+		home := homeRegion(usage)
+		for _, e := range usage.Errors {
+			if home == "" || e.Region == "" || e.Region == home {
+				failed[e.Collector] = true
+			}
+		}
+	}
+	for path := range metrics {
+		if failed[metricCollector(path)] {
+			delete(metrics, path)
+		}
+	}
+	return metrics
+}
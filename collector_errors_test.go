@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeServiceError imita los errores de servicio del SDK de OCI
+type fakeServiceError struct {
+	status    int
+	code      string
+	message   string
+	requestID string
+}
+
+func (e fakeServiceError) Error() string           { return e.message }
+func (e fakeServiceError) GetHTTPStatusCode() int  { return e.status }
+func (e fakeServiceError) GetMessage() string      { return e.message }
+func (e fakeServiceError) GetCode() string         { return e.code }
+func (e fakeServiceError) GetOpcRequestID() string { return e.requestID }
+
+func TestNewCollectorError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want CollectorError
+	}{
+		{
+			name: "error de servicio",
+			err: ociError("core", "ListInstances", fakeServiceError{
+				status: 401, code: "NotAuthenticated", message: "The required information to complete authentication was not provided", requestID: "ABC123",
+			}),
+			want: CollectorError{
+				Collector:    "compute",
+				Service:      "core",
+				Operation:    "ListInstances",
+				HTTPStatus:   401,
+				Code:         "NotAuthenticated",
+				OpcRequestID: "ABC123",
+				Message:      "The required information to complete authentication was not provided",
+			},
+		},
+		{
+			name: "timeout",
+			err:  ociError("core", "ListInstances", fmt.Errorf("request failed: %w", context.DeadlineExceeded)),
+			want: CollectorError{
+				Collector: "compute",
+				Service:   "core",
+				Operation: "ListInstances",
+				Message:   "core ListInstances: request failed: context deadline exceeded",
+				TimedOut:  true,
+			},
+		},
+		{
+			name: "error sin información de OCI",
+			err:  errors.New("boom"),
+			want: CollectorError{Collector: "compute", Message: "boom"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newCollectorError("compute", tt.err); got != tt.want {
+				t.Errorf("newCollectorError() = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReliableUsageMetrics(t *testing.T) {
+	usage := &AllUsage{Errors: []CollectorError{{Collector: "compute"}}}
+	metrics := reliableUsageMetrics(usage)
+
+	for path := range metrics {
+		if path == "compute.arm.ocpus" || path == "compute.amd.instances" {
+			t.Errorf("metric %s from a failed collector should be skipped", path)
+		}
+	}
+	if _, ok := metrics["blockStorage.total"]; !ok {
+		t.Error("metrics from healthy collectors should be kept")
+	}
+}
+
+// TestStatusHandlerUnreliableData verifica que /status no devuelve 200 si falta algún colector
+func TestStatusHandlerUnreliableData(t *testing.T) {
+	setTestOCIEnv(t)
+
+	tests := []struct {
+		name   string
+		errors []CollectorError
+		want   int
+	}{
+		{name: "datos completos", want: http.StatusOK},
+		{name: "colector caído", errors: []CollectorError{{Collector: "publicIPs", Message: "boom"}}, want: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := &AllUsage{Errors: tt.errors}
+			poller = newUsagePoller(time.Minute, func(ctx context.Context) (*AllUsage, error) { return usage, nil })
+			defer func() { poller = nil }()
+
+			rec := httptest.NewRecorder()
+			statusHandler(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d; want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Package main - Este archivo recorre el árbol de compartimentos de la tenancy
+// Los límites Always Free son de toda la tenancy, no de un compartimento
+package main
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// Compartment es un compartimento a recolectar
+type Compartment struct {
+	ID   string
+	Name string
+	Path string // ruta desde la raíz del escaneo, ej. "prod/app"
+}
+
+// CompartmentUsage es el desglose de uso de un compartimento en /usage
+type CompartmentUsage struct {
+	ID     string             `json:"id"`
+	Name   string             `json:"name"`
+	Path   string             `json:"path"`
+	Used   map[string]float64 `json:"used"` // ruta de la métrica → uso
+	Errors []CollectorError   `json:"errors,omitempty"`
+}
+
+// compartmentScanRecursive indica si hay que recorrer todo el árbol
+// OCI_COMPARTMENT_SCAN=recursive; por defecto solo se mira getCompartmentID()
+func compartmentScanRecursive() bool {
+	return strings.EqualFold(os.Getenv("OCI_COMPARTMENT_SCAN"), "recursive")
+}
+
+// resolveCompartments devuelve los compartimentos a recolectar
+// En modo recursivo son la raíz (getCompartmentID) y todos sus descendientes activos
+func resolveCompartments(ctx context.Context, backend ociBackend, pagination paginationConfig) ([]Compartment, error) {
+	rootID := getCompartmentID()
+	if !compartmentScanRecursive() {
+		return []Compartment{{ID: rootID}}, nil
+	}
+
+	tree, err := listCompartmentTree(ctx, backend, getTenancyID(), pagination)
+	if err != nil {
+		return nil, err
+	}
+	return selectSubtree(rootID, tree), nil
+}
+
+// listCompartmentTree lista todos los compartimentos activos de la tenancy
+// Identity solo permite CompartmentIdInSubtree sobre la raíz de la tenancy
+func listCompartmentTree(ctx context.Context, backend ociBackend, tenancyID string, pagination paginationConfig) ([]identity.Compartment, error) {
+	client, err := backend.Identity()
+	if err != nil {
+		return nil, err
+	}
+
+	var compartments []identity.Compartment
+	truncated, err := pagination.paginate(func(page *string) (*string, error) {
+		response, err := client.ListCompartments(ctx, identity.ListCompartmentsRequest{
+			CompartmentId:          common.String(tenancyID),
+			CompartmentIdInSubtree: common.Bool(true),
+			AccessLevel:            identity.ListCompartmentsAccessLevelAccessible,
+			LifecycleState:         identity.CompartmentLifecycleStateActive,
+			Limit:                  common.Int(pagination.PageLimit),
+			Page:                   page,
+		})
+		if err != nil {
+			return nil, ociError("identity", "ListCompartments", err)
+		}
+		compartments = append(compartments, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		logger.Warn().Int("max_pages", pagination.MaxPages).Msg("Compartment list truncated, some compartments will not be scanned")
+	}
+	return compartments, nil
+}
+
+// selectSubtree devuelve rootID y sus descendientes, con su ruta, ordenados por ruta
+// tree es la lista plana de Identity: el padre de cada compartimento está en CompartmentId
+func selectSubtree(rootID string, tree []identity.Compartment) []Compartment {
+	children := map[string][]identity.Compartment{}
+	rootName := "root"
+	for _, c := range tree {
+		if c.Id == nil || c.CompartmentId == nil {
+			continue
+		}
+		children[*c.CompartmentId] = append(children[*c.CompartmentId], c)
+		if *c.Id == rootID && c.Name != nil {
+			rootName = *c.Name
+		}
+	}
+
+	selected := []Compartment{{ID: rootID, Name: rootName, Path: "/"}}
+
+	// Recorrido en anchura desde la raíz
+	type node struct{ id, path string }
+	queue := []node{{id: rootID}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range children[current.id] {
+			name := *child.Id
+			if child.Name != nil {
+				name = *child.Name
+			}
+			path := current.path + "/" + name
+			selected = append(selected, Compartment{ID: *child.Id, Name: name, Path: path})
+			queue = append(queue, node{id: *child.Id, path: path})
+		}
+	}
+
+	sort.SliceStable(selected[1:], func(i, j int) bool {
+		return selected[i+1].Path < selected[j+1].Path
+	})
+	return selected
+}
+
+// collectCompartments recolecta cada compartimento y suma los resultados
+// Con un solo compartimento el resultado es idéntico al de collectCompartment
+func collectCompartments(ctx context.Context, backend ociBackend, compartments []Compartment, pagination paginationConfig, timeouts timeoutConfig) *AllUsage {
+	if len(compartments) == 1 && !compartmentScanRecursive() {
+		return collectCompartment(ctx, backend, compartments[0].ID, pagination, timeouts)
+	}
+
+	// Un semáforo limita cuántos compartimentos se consultan a la vez,
+	// para no chocar con los límites de peticiones de la API
+	parts := make([]*AllUsage, len(compartments))
+	semaphore := make(chan struct{}, getEnvInt("OCI_COMPARTMENT_CONCURRENCY", 4))
+	var wg sync.WaitGroup
+	for i, compartment := range compartments {
+		wg.Add(1)
+		go func(i int, compartment Compartment) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			part := collectCompartment(ctx, backend, compartment.ID, pagination, timeouts)
+			for j := range part.Errors {
+				part.Errors[j].Compartment = compartment.Path
+			}
+			parts[i] = part
+		}(i, compartment)
+	}
+	wg.Wait()
+
+	usage := mergeUsage(parts...)
+	for i, compartment := range compartments {
+		usage.Compartments = append(usage.Compartments, CompartmentUsage{
+			ID:     compartment.ID,
+			Name:   compartment.Name,
+			Path:   compartment.Path,
+			Used:   usedByPath(parts[i]),
+			Errors: parts[i].Errors,
+		})
+	}
+	return usage
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// compartment crea un compartimento de Identity para los tests
+func compartment(id, parent, name string) identity.Compartment {
+	return identity.Compartment{Id: common.String(id), CompartmentId: common.String(parent), Name: common.String(name)}
+}
+
+func TestSelectSubtree(t *testing.T) {
+	tree := []identity.Compartment{
+		compartment("ocid.sandbox", "ocid.tenancy", "sandbox"),
+		compartment("ocid.prod", "ocid.tenancy", "prod"),
+		compartment("ocid.prod.app", "ocid.prod", "app"),
+		compartment("ocid.lab", "ocid.tenancy", "lab"),
+	}
+
+	tests := []struct {
+		name   string
+		rootID string
+		want   []string
+	}{
+		{"desde la tenancy", "ocid.tenancy", []string{"/", "/lab", "/prod", "/prod/app", "/sandbox"}},
+		{"desde un subcompartimento", "ocid.prod", []string{"/", "/app"}},
+		{"hoja", "ocid.lab", []string{"/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			for _, c := range selectSubtree(tt.rootID, tree) {
+				paths = append(paths, c.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("paths = %v; want %v", paths, tt.want)
+			}
+		})
+	}
+
+	if got := selectSubtree("ocid.prod", tree)[0]; got.ID != "ocid.prod" || got.Name != "prod" {
+		t.Errorf("root = %+v; want prod", got)
+	}
+}
+
+// TestResolveCompartmentsRecursive recorre el árbol de compartimentos con el doble de OCI
+func TestResolveCompartmentsRecursive(t *testing.T) {
+	t.Setenv("OCI_COMPARTMENT_SCAN", "recursive")
+	t.Setenv("OCI_TENANCY_ID", "ocid.tenancy")
+	t.Setenv("OCI_COMPARTMENT_ID", "ocid.prod")
+
+	deleted := compartment("ocid.prod.old", "ocid.prod", "old")
+	deleted.LifecycleState = identity.CompartmentLifecycleStateDeleted
+	scenario := &ociScenario{Compartments: []identity.Compartment{
+		compartment("ocid.prod", "ocid.tenancy", "prod"),
+		compartment("ocid.prod.app", "ocid.prod", "app"),
+		compartment("ocid.prod.app.db", "ocid.prod.app", "db"),
+		compartment("ocid.lab", "ocid.tenancy", "lab"),
+		deleted,
+	}}
+	for i := range scenario.Compartments[:4] {
+		scenario.Compartments[i].LifecycleState = identity.CompartmentLifecycleStateActive
+	}
+	backend := newScenarioBackend(scenario)
+
+	got, err := resolveCompartments(context.Background(), backend, paginationConfig{PageLimit: 2, MaxPages: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, c := range got {
+		paths = append(paths, c.Path)
+	}
+	if want := []string{"/", "/app", "/app/db"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %v; want %v", paths, want)
+	}
+	if calls := backend.callCount("ListCompartments"); calls != 2 {
+		t.Errorf("ListCompartments calls = %d; want 2 pages of 2", calls)
+	}
+
+	scenario.Errors = map[string]scenarioError{"ListCompartments": {Status: 401, Code: "NotAuthenticated", Message: "not authenticated"}}
+	_, err = resolveCompartments(context.Background(), backend, testPagination)
+	var ociErr *ociCallError
+	if !errors.As(err, &ociErr) || ociErr.Operation != "ListCompartments" {
+		t.Errorf("err = %v; want an OCIError for ListCompartments", err)
+	}
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+	"github.com/oracle/oci-go-sdk/v65/limits"
+)
+
+// startEndToEnd arranca el doble de OCI con un escenario y el watcher completo apuntando a él
+// Las peticiones al watcher pasan por authMiddleware y el SDK real firma las llamadas a OCI
+func startEndToEnd(t *testing.T, scenario *ociScenario) (*httptest.Server, *scenarioBackend) {
+	t.Helper()
+
+	backend := newScenarioBackend(scenario)
+	stub := httptest.NewServer(ociStubHandler(backend))
+	t.Cleanup(stub.Close)
+
+	// El SDK necesita una clave RSA válida para firmar, aunque el doble no la compruebe
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	clearOCIEnv(t)
+	setTestOCIEnv(t)
+	t.Setenv("OCI_PRIVATE_KEY_PATH", keyPath)
+	t.Setenv("OCI_COMPARTMENT_ID", testCompartment)
+	t.Setenv("OCI_ENDPOINT_OVERRIDE", stub.URL)
+	t.Setenv("API_KEY", "e2e-secret")
+
+	poller = newUsagePoller(time.Minute, getOCIUsage)
+	t.Cleanup(func() { poller = nil })
+
+	watcher := httptest.NewServer(newRouter())
+	t.Cleanup(watcher.Close)
+	return watcher, backend
+}
+
+// getJSON hace un GET al watcher y decodifica la respuesta
+func getJSON(t *testing.T, url, apiKey string, out any) int {
+	t.Helper()
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apiKey != "" {
+		request.Header.Set("X-API-Key", apiKey)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+			t.Fatalf("GET %s: invalid JSON: %v", url, err)
+		}
+	}
+	return response.StatusCode
+}
+
+func TestEndToEndStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		fixture    string
+		errors     map[string]scenarioError
+		apiKey     string
+		wantCode   int
+		wantStatus string
+	}{
+		{name: "sin API key", fixture: "free_tier.json", wantCode: http.StatusUnauthorized},
+		{name: "dentro del free tier", fixture: "free_tier.json", apiKey: "e2e-secret", wantCode: http.StatusOK, wantStatus: SeverityOK},
+		{name: "por encima de los límites", fixture: "over_limits.json", apiKey: "e2e-secret", wantCode: http.StatusOK, wantStatus: SeverityCritical},
+		{
+			name:       "OCI rechaza las credenciales",
+			fixture:    "free_tier.json",
+			errors:     map[string]scenarioError{"ListInstances": {Status: 401, Code: "NotAuthenticated", Message: "not authenticated"}},
+			apiKey:     "e2e-secret",
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: SeverityDegraded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario := loadFixture(t, tt.fixture)
+			scenario.Errors = tt.errors
+			watcher, _ := startEndToEnd(t, scenario)
+
+			var status StatusResponse
+			code := getJSON(t, watcher.URL+"/status", tt.apiKey, &status)
+			if code != tt.wantCode {
+				t.Fatalf("GET /status = %d; want %d", code, tt.wantCode)
+			}
+			if tt.wantStatus != "" && status.Status != tt.wantStatus {
+				t.Errorf("status = %s; want %s (%+v)", status.Status, tt.wantStatus, status)
+			}
+		})
+	}
+}
+
+// TestEndToEndUsage verifica el JSON de /usage y la paginación a través del SDK real
+func TestEndToEndUsage(t *testing.T) {
+	watcher, backend := startEndToEnd(t, loadFixture(t, "free_tier.json"))
+	t.Setenv("OCI_PAGE_LIMIT", "1")
+
+	var usage struct {
+		Usage AllUsage `json:"usage"`
+	}
+	if code := getJSON(t, watcher.URL+"/usage", "e2e-secret", &usage); code != http.StatusOK {
+		t.Fatalf("GET /usage = %d; want 200", code)
+	}
+
+	got := usage.Usage
+	if got.Compute.ARM.Instances != 2 || got.Compute.ARM.OCPUs.Used != 2 {
+		t.Errorf("ARM = %+v; want 2 instances with 2 OCPUs", got.Compute.ARM)
+	}
+	if got.BlockStorage.Total.Used != 104 {
+		t.Errorf("BlockStorage.Total.Used = %v; want 104", got.BlockStorage.Total.Used)
+	}
+	if got.ObjectStorage.Total.Used != 3 {
+		t.Errorf("ObjectStorage.Total.Used = %v; want 3", got.ObjectStorage.Total.Used)
+	}
+	if got.PublicIPs.Used != 1 {
+		t.Errorf("PublicIPs.Used = %v; want 1", got.PublicIPs.Used)
+	}
+	if calls := backend.callCount("ListInstances"); calls != 3 {
+		t.Errorf("ListInstances calls = %d; want 3 pages of 1", calls)
+	}
+}
+
+// TestEndToEndLaunch lanza una instancia con el CLI a través del SDK real y el doble de OCI
+func TestEndToEndLaunch(t *testing.T) {
+	scenario := loadFixture(t, "free_tier.json")
+	launch := launchScenario()
+	scenario.AvailabilityDomains, scenario.FaultDomains = launch.AvailabilityDomains, launch.FaultDomains
+	scenario.Errors = map[string]scenarioError{
+		"LaunchInstance:Uocm:EU-MADRID-1-AD-1/FAULT-DOMAIN-1": {Status: http.StatusInternalServerError, Code: "InternalError", Message: "Out of host capacity."},
+	}
+	_, backend := startEndToEnd(t, scenario)
+
+	template := filepath.Join(t.TempDir(), "launch.yaml")
+	writeTemplate := func(ocpus int) {
+		data := fmt.Sprintf("ocpus: %d\nmemoryGB: 12\nimageId: ocid1.image.oc1..test\nsubnetId: ocid1.subnet.oc1..test\nsshPublicKey: ssh-ed25519 AAAA test\n", ocpus)
+		if err := os.WriteFile(template, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 2 OCPUs en uso + 3 pedidas superan las 4 de la Free Tier
+	writeTemplate(3)
+	var out strings.Builder
+	if code := runLaunchCommand([]string{"-template", template}, &out); code != exitCritical {
+		t.Fatalf("exit code = %d; want %d (%s)", code, exitCritical, out.String())
+	}
+	if backend.callCount("LaunchInstance") != 0 {
+		t.Error("LaunchInstance called despite the headroom refusal")
+	}
+
+	writeTemplate(2)
+	out.Reset()
+	if code := runLaunchCommand([]string{"-template", template}, &out); code != exitOK {
+		t.Fatalf("exit code = %d; want 0 (%s)", code, out.String())
+	}
+	if !strings.Contains(out.String(), "LAUNCHED") || !strings.Contains(out.String(), "AD-1") {
+		t.Errorf("output = %q", out.String())
+	}
+	if calls := backend.callCount("LaunchInstance:Uocm:EU-MADRID-1-AD-1/FAULT-DOMAIN-2"); calls != 1 {
+		t.Errorf("second placement tried %d times; want 1", calls)
+	}
+}
+
+// TestEndToEndCapacity consulta /capacity a través del SDK real y lo guarda en el histórico
+func TestEndToEndCapacity(t *testing.T) {
+	scenario := loadFixture(t, "free_tier.json")
+	scenario.AvailabilityDomains = launchScenario().AvailabilityDomains
+	scenario.Capacity = map[string]string{"Uocm:EU-MADRID-1-AD-1": CapacityAvailable}
+	watcher, _ := startEndToEnd(t, scenario)
+
+	store, err := openHistoryStore(filepath.Join(t.TempDir(), "history.db"), time.Hour, 24*time.Hour, 48*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history = store
+	t.Cleanup(func() {
+		store.Close()
+		history = nil
+	})
+
+	var response CapacityResponse
+	if code := getJSON(t, watcher.URL+"/capacity?ocpus=2&memoryGB=12", "e2e-secret", &response); code != http.StatusOK {
+		t.Fatalf("GET /capacity = %d; want 200", code)
+	}
+	if response.Report.OCPUs != 2 || len(response.Report.Results) != 2 || !response.Report.Available() {
+		t.Errorf("report = %+v", response.Report)
+	}
+	if len(response.Recent) != 2 || response.Recent[0].LastAvailable == "" || response.Recent[1].LastAvailable != "" {
+		t.Errorf("recent = %+v", response.Recent)
+	}
+	if stored, _ := history.CapacityHistory(time.Now().Add(-time.Hour)); len(stored) != 1 {
+		t.Errorf("capacity reports in history = %d; want 1", len(stored))
+	}
+	if code := getJSON(t, watcher.URL+"/capacity?ocpus=-1", "e2e-secret", &response); code != http.StatusBadRequest {
+		t.Errorf("GET /capacity?ocpus=-1 = %d; want 400", code)
+	}
+}
+
+// TestEndToEndPlan evalúa un conjunto de cambios con POST /plan sobre el uso recolectado
+func TestEndToEndPlan(t *testing.T) {
+	watcher, _ := startEndToEnd(t, loadFixture(t, "free_tier.json"))
+
+	post := func(body string) (int, PlanResult) {
+		t.Helper()
+		request, err := http.NewRequest(http.MethodPost, watcher.URL+"/plan", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		request.Header.Set("X-API-Key", "e2e-secret")
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		var result PlanResult
+		json.NewDecoder(response.Body).Decode(&result)
+		return response.StatusCode, result
+	}
+
+	// free_tier.json: 2 OCPUs y 12 GB de A1 en uso, 104 GB de bloque
+	code, result := post(`{"changes":[{"type":"instance","ocpus":2,"memoryGB":12}]}`)
+	if code != http.StatusOK || !result.Fits || result.After.ARMOCPUs != 0 {
+		t.Errorf("POST /plan (fits) = %d %+v", code, result)
+	}
+	code, result = post(`{"changes":[{"type":"instance","ocpus":3,"memoryGB":12}]}`)
+	if code != http.StatusOK || result.Fits || len(result.Exceeded) != 1 {
+		t.Errorf("POST /plan (exceeds) = %d %+v", code, result)
+	}
+	if code, _ := post(`{"changes":[{"type":"instance","shape":"VM.Standard.E4.Flex","ocpus":1,"memoryGB":8}]}`); code != http.StatusBadRequest {
+		t.Errorf("POST /plan (paid shape) = %d; want 400", code)
+	}
+
+	var current PlanResult
+	if code := getJSON(t, watcher.URL+"/plan", "e2e-secret", &current); code != http.StatusOK || len(current.Suggestions) == 0 {
+		t.Errorf("GET /plan = %d %+v", code, current)
+	}
+}
+
+// TestEndToEndScans recorre compartimentos y regiones y consulta /limits a través del SDK real:
+// ninguna llamada sale del doble de OCI
+func TestEndToEndScans(t *testing.T) {
+	scenario := loadFixture(t, "free_tier.json")
+	scenario.Compartments = []identity.Compartment{
+		{Id: common.String(testCompartment), CompartmentId: common.String("ocid1.tenancy.test"), Name: common.String("test"), LifecycleState: identity.CompartmentLifecycleStateActive},
+		{Id: common.String("ocid1.compartment.oc1..child"), CompartmentId: common.String(testCompartment), Name: common.String("child"), LifecycleState: identity.CompartmentLifecycleStateActive},
+	}
+	scenario.RegionSubscriptions = []identity.RegionSubscription{
+		{RegionName: common.String("eu-madrid-1"), Status: identity.RegionSubscriptionStatusReady, IsHomeRegion: common.Bool(true)},
+	}
+	scenario.Limits = map[string][]scenarioLimit{
+		"compute/standard-a1-core-count": {{
+			LimitValueSummary: limits.LimitValueSummary{ScopeType: limits.LimitValueSummaryScopeTypeAd, AvailabilityDomain: common.String("Uocm:EU-MADRID-1-AD-1"), Value: common.Int64(4)},
+			Used:              common.Int64(2),
+			Available:         common.Int64(2),
+		}},
+	}
+	watcher, backend := startEndToEnd(t, scenario)
+	t.Setenv("OCI_COMPARTMENT_SCAN", "recursive")
+	t.Setenv("OCI_REGION_SCAN", "all")
+
+	var usage struct {
+		Usage AllUsage `json:"usage"`
+	}
+	if code := getJSON(t, watcher.URL+"/usage", "e2e-secret", &usage); code != http.StatusOK {
+		t.Fatalf("GET /usage = %d; want 200", code)
+	}
+	if len(usage.Usage.Compartments) != 2 || len(usage.Usage.Regions) != 1 {
+		t.Errorf("compartments = %d, regions = %d; want 2 and 1", len(usage.Usage.Compartments), len(usage.Usage.Regions))
+	}
+	for _, operation := range []string{"ListCompartments", "ListRegionSubscriptions"} {
+		if backend.callCount(operation) == 0 {
+			t.Errorf("%s not sent to the stub", operation)
+		}
+	}
+
+	// El informe queda en la caché global: se vacía para no afectar a otros tests
+	t.Cleanup(func() {
+		serviceLimitsCache.Lock()
+		serviceLimitsCache.report = nil
+		serviceLimitsCache.Unlock()
+	})
+	var response LimitsResponse
+	if code := getJSON(t, watcher.URL+"/limits?refresh=true", "e2e-secret", &response); code != http.StatusOK {
+		t.Fatalf("GET /limits = %d; want 200", code)
+	}
+	report := response.ServiceLimits
+	if report == nil || report.Error != "" || len(report.Limits) == 0 {
+		t.Fatalf("serviceLimits = %+v; want a report from the stub", report)
+	}
+	if ocpus := report.Limits[0]; ocpus.ServiceLimit != 4 || ocpus.Used != 2 || ocpus.Error != "" {
+		t.Errorf("ocpus = %+v; want limit 4 and used 2", ocpus)
+	}
+}
+
+// TestOCIStubConcurrentLaunch lanza instancias y lista las existentes a la vez, como hacen
+// `launch` y /usage contra el mismo doble (ejecutar con -race)
+func TestOCIStubConcurrentLaunch(t *testing.T) {
+	scenario := loadFixture(t, "free_tier.json")
+	before := len(scenario.Instances)
+	backend := newScenarioBackend(scenario)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			backend.LaunchInstance(context.Background(), core.LaunchInstanceRequest{
+				LaunchInstanceDetails: core.LaunchInstanceDetails{CompartmentId: common.String(testCompartment)},
+				OpcRetryToken:         common.String(fmt.Sprintf("token-%d", i)),
+			})
+		}(i)
+		go func() {
+			defer wg.Done()
+			backend.ListInstances(context.Background(), core.ListInstancesRequest{CompartmentId: common.String(testCompartment)})
+		}()
+	}
+	wg.Wait()
+
+	response, err := backend.ListInstances(context.Background(), core.ListInstancesRequest{CompartmentId: common.String(testCompartment)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(response.Items); got != before+10 {
+		t.Errorf("instances = %d; want %d", got, before+10)
+	}
+}
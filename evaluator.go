@@ -0,0 +1,318 @@
+// Package main - Este archivo contiene el evaluador de estado compartido por
+// /usage, /status, /metrics, el CLI y las notificaciones
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Niveles de severidad, de menor a mayor
+// DEGRADED y UNKNOWN no dependen del uso sino de la fiabilidad de los datos:
+// DEGRADED si algún colector falló, UNKNOWN si fallaron todos
+const (
+	SeverityOK        = "OK"
+	SeverityAttention = "ATTENTION"
+	SeverityWarning   = "WARNING"
+	SeverityDegraded  = "DEGRADED"
+	SeverityCritical  = "CRITICAL"
+	SeverityUnknown   = "UNKNOWN"
+)
+
+// severityRank permite comparar severidades
+// Un CRITICAL confirmado pesa más que unos datos incompletos
+var severityRank = map[string]int{
+	SeverityOK:        0,
+	SeverityAttention: 1,
+	SeverityWarning:   2,
+	SeverityDegraded:  3,
+	SeverityCritical:  4,
+	SeverityUnknown:   5,
+}
+
+// Finding es un hallazgo sobre un recurso concreto
+type Finding struct {
+	Resource    string   `json:"resource"` // ruta de la métrica, ej. "blockStorage.total"
+	Name        string   `json:"name"`
+	Severity    string   `json:"severity"`
+	Percentage  int      `json:"percentage"`
+	Threshold   int      `json:"threshold"` // umbral que disparó la severidad
+	Used        float64  `json:"used"`
+	Limit       float64  `json:"limit"`
+	ResourceIDs []string `json:"resourceIds,omitempty"`
+	Message     string   `json:"message"`
+}
+
+// Evaluation es el resultado de evaluar un snapshot de uso
+type Evaluation struct {
+	Status        string           `json:"status"`
+	MaxPercentage int              `json:"maxUsagePercentage"`
+	Findings      []Finding        `json:"findings"`
+	Errors        []CollectorError `json:"errors,omitempty"`
+}
+
+// Reliable indica si los datos de la evaluación son completos
+func (e Evaluation) Reliable() bool {
+	return len(e.Errors) == 0
+}
+
+// Warnings devuelve los mensajes de los hallazgos de nivel WARNING o superior
+// y de los colectores que fallaron
+func (e Evaluation) Warnings() []string {
+	warnings := []string{}
+	for _, f := range e.Findings {
+		if severityRank[f.Severity] >= severityRank[SeverityWarning] {
+			warnings = append(warnings, f.Message)
+		}
+	}
+	for _, ce := range e.Errors {
+		warnings = append(warnings, fmt.Sprintf("%s collector failed: %s", ce.Collector, ce.Message))
+	}
+	return warnings
+}
+
+// usageCheck describe una métrica que participa en el estado general
+type usageCheck struct {
+	path string
+	name string
+	ids  func(usage *AllUsage) []string
+	// maxSeverity limita la severidad de métricas que no son uso real (p. ej. proyecciones)
+	maxSeverity string
+}
+
+// usageChecks es la lista de métricas evaluadas
+// Para vigilar un recurso nuevo basta con añadirlo aquí
+var usageChecks = []usageCheck{
+	{path: "compute.arm.ocpus", name: "ARM OCPUs", ids: instanceIDs("arm")},
+	{path: "compute.arm.memoryGB", name: "ARM Memory", ids: instanceIDs("arm")},
+	{path: "compute.amd.instances", name: "AMD Micro instances", ids: instanceIDs("amd")},
+	{path: "blockStorage.total", name: "Block Storage", ids: volumeIDs},
+	{path: "objectStorage.total", name: "Object Storage", ids: bucketNames},
+	{path: "objectStorage.requests", name: "Object Storage requests (month to date)", ids: bucketNames},
+	{path: "objectStorage.projectedRequests", name: "Object Storage requests (projected)", ids: bucketNames, maxSeverity: SeverityWarning},
+	{path: "publicIPs", name: "Public IPs"},
+	{path: "loadBalancer.count", name: "Load Balancers", ids: loadBalancerIDs},
+	{path: "database.autonomousDBs", name: "Autonomous Databases", ids: databaseIDs(true)},
+	{path: "database.storageGB", name: "Autonomous Database Storage", ids: databaseIDs(true)},
+	{path: "bandwidth.egressTB", name: "Outbound data transfer (month to date)"},
+	{path: "bandwidth.projectedTB", name: "Outbound data transfer (projected)", maxSeverity: SeverityWarning},
+}
+
+// evaluateUsage calcula el estado general y los hallazgos de un snapshot
+// Las métricas de los colectores que fallaron no se evalúan (no son 0, son desconocidas)
+func evaluateUsage(usage *AllUsage) Evaluation {
+	metrics := reliableUsageMetrics(usage)
+	eval := Evaluation{Status: SeverityOK, Findings: []Finding{}}
+
+	for _, check := range usageChecks {
+		m, ok := metrics[check.path]
+		if !ok {
+			continue
+		}
+		// Las proyecciones (con maxSeverity) no son uso real: no cuentan para el máximo
+		if check.maxSeverity == "" && m.Percentage > eval.MaxPercentage {
+			eval.MaxPercentage = m.Percentage
+		}
+
+		severity, threshold := thresholds.For(check.path).Severity(m.Percentage)
+		if check.maxSeverity != "" && severityRank[severity] > severityRank[check.maxSeverity] {
+			severity = check.maxSeverity
+		}
+		if severity == SeverityOK {
+			continue
+		}
+
+		finding := Finding{
+			Resource:   check.path,
+			Name:       check.name,
+			Severity:   severity,
+			Percentage: m.Percentage,
+			Threshold:  threshold,
+			Used:       m.Used,
+			Limit:      m.Limit,
+			Message: fmt.Sprintf("%s at %d%% (%g of %g, %s threshold %d%%)",
+				check.name, m.Percentage, m.Used, m.Limit, severity, threshold),
+		}
+		if check.ids != nil {
+			finding.ResourceIDs = check.ids(usage)
+		}
+		eval.Findings = append(eval.Findings, finding)
+
+		if severityRank[severity] > severityRank[eval.Status] {
+			eval.Status = severity
+		}
+	}
+
+	// Fuera de la región principal no hay Always Free: cualquier uso se factura
+	// Lo mismo pasa con las Autonomous Databases que no son Always Free
+	// Además, las instancias inactivas pueden perderse si Oracle las reclama
+	// y cualquier shape o configuración de pago (ver billing.go) se factura aunque haya margen
+	findings := append(outsideHomeFindings(usage), paidDatabaseFindings(usage)...)
+	findings = append(findings, reclamationFindings(usage)...)
+	findings = append(findings, billableFindings(usage)...)
+	for _, finding := range findings {
+		eval.Findings = append(eval.Findings, finding)
+		if severityRank[finding.Severity] > severityRank[eval.Status] {
+			eval.Status = finding.Severity
+		}
+	}
+
+	// Los fallos de colectores degradan el estado; si fallaron todos no sabemos nada
+	if usage != nil && len(usage.Errors) > 0 {
+		eval.Errors = usage.Errors
+		degraded := SeverityDegraded
+		if len(failedCollectors(usage)) >= len(collectorNames) {
+			degraded = SeverityUnknown
+		}
+		if severityRank[degraded] > severityRank[eval.Status] {
+			eval.Status = degraded
+		}
+	}
+
+	// Los más graves primero
+	sort.SliceStable(eval.Findings, func(i, j int) bool {
+		return severityRank[eval.Findings[i].Severity] > severityRank[eval.Findings[j].Severity]
+	})
+
+	return eval
+}
+
+// instanceIDs devuelve una función que lista los OCIDs de las instancias de una arquitectura
+func instanceIDs(arch string) func(usage *AllUsage) []string {
+	return func(usage *AllUsage) []string {
+		var ids []string
+		for _, instance := range usage.Compute.Instances {
+			if instance.Arch == arch {
+				ids = append(ids, instance.ID)
+			}
+		}
+		return ids
+	}
+}
+
+// volumeIDs lista los OCIDs de todos los volúmenes
+func volumeIDs(usage *AllUsage) []string {
+	var ids []string
+	for _, vol := range usage.BlockStorage.Volumes {
+		ids = append(ids, vol.ID)
+	}
+	return ids
+}
+
+// bucketNames lista los nombres de los buckets (Object Storage no usa OCIDs en sus rutas)
+func bucketNames(usage *AllUsage) []string {
+	var names []string
+	for _, bucket := range usage.ObjectStorage.Buckets {
+		names = append(names, bucket.Name)
+	}
+	return names
+}
+
+// loadBalancerIDs lista los OCIDs de los load balancers
+func loadBalancerIDs(usage *AllUsage) []string {
+	var ids []string
+	for _, lb := range usage.LoadBalancer.LoadBalancers {
+		ids = append(ids, lb.ID)
+	}
+	return ids
+}
+
+// databaseIDs devuelve una función que lista los OCIDs de las Autonomous Databases
+// Always Free (free=true) o de pago (free=false)
+func databaseIDs(free bool) func(usage *AllUsage) []string {
+	return func(usage *AllUsage) []string {
+		var ids []string
+		for _, db := range usage.Database.Databases {
+			if db.IsFreeTier == free {
+				ids = append(ids, db.ID)
+			}
+		}
+		return ids
+	}
+}
+
+// paidDatabaseFindings genera un hallazgo CRITICAL si hay Autonomous Databases de pago
+func paidDatabaseFindings(usage *AllUsage) []Finding {
+	if usage == nil || usage.Database.Paid == 0 {
+		return nil
+	}
+	ids := databaseIDs(false)(usage)
+	return []Finding{{
+		Resource:    "database.paid",
+		Name:        "Paid Autonomous Databases",
+		Severity:    SeverityCritical,
+		Used:        float64(usage.Database.Paid),
+		ResourceIDs: ids,
+		Message: fmt.Sprintf("%d Autonomous Database(s) are not Always Free and are billed (%s)",
+			usage.Database.Paid, strings.Join(ids, ", ")),
+	}}
+}
+
+// reclamationFindings genera un hallazgo por cada instancia Always Free inactiva
+// CRITICAL si ya cumple la regla de reclamación de Oracle, WARNING si lleva días inactiva
+func reclamationFindings(usage *AllUsage) []Finding {
+	var findings []Finding
+	if usage == nil {
+		return findings
+	}
+	for _, r := range usage.Reclamation.Instances {
+		finding := Finding{
+			Resource:    "reclamation." + r.InstanceID,
+			Name:        "Idle instance " + r.Name,
+			Used:        float64(r.IdleDays),
+			Limit:       7,
+			ResourceIDs: []string{r.InstanceID},
+		}
+		switch r.Risk {
+		case ReclamationReclaimable:
+			finding.Severity = SeverityCritical
+			finding.Message = fmt.Sprintf("Instance %s has been idle for 7 days (p95 below %g%%) and can be reclaimed by Oracle",
+				r.Name, reclamationIdlePercent)
+		case ReclamationAtRisk:
+			finding.Severity = SeverityWarning
+			finding.Message = fmt.Sprintf("Instance %s has been idle for %d day(s), reclaimable by Oracle in %d day(s)",
+				r.Name, r.IdleDays, r.DaysRemaining)
+		default:
+			continue
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// outsideHomeFindings genera un hallazgo CRITICAL por cada región distinta de la
+// principal que tenga algún recurso
+func outsideHomeFindings(usage *AllUsage) []Finding {
+	var findings []Finding
+	if usage == nil {
+		return findings
+	}
+	home := homeRegion(usage)
+
+	for _, region := range usage.Regions {
+		if region.Home {
+			continue
+		}
+		var used []string
+		for path, value := range region.Used {
+			if value > 0 {
+				used = append(used, path)
+			}
+		}
+		if len(used) == 0 {
+			continue
+		}
+		sort.Strings(used)
+
+		findings = append(findings, Finding{
+			Resource:    "regions." + region.Region,
+			Name:        "Resources outside home region",
+			Severity:    SeverityCritical,
+			ResourceIDs: resourcesInRegion(usage, region.Region),
+			Message: fmt.Sprintf("Billable resources in %s outside home region %s (%s)",
+				region.Region, home, strings.Join(used, ", ")),
+		})
+	}
+	return findings
+}
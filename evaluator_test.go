@@ -0,0 +1,175 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestEvaluateUsage verifica el estado, el máximo y los hallazgos del evaluador compartido
+func TestEvaluateUsage(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(u *AllUsage)
+		wantStatus   string
+		wantMax      int
+		wantFindings []string // rutas de los hallazgos, en orden
+		wantWarnings int
+	}{
+		{
+			name:       "todo a cero",
+			setup:      func(u *AllUsage) {},
+			wantStatus: SeverityOK,
+		},
+		{
+			name: "ARM al 50% no genera hallazgos",
+			setup: func(u *AllUsage) {
+				u.Compute.ARM.OCPUs = UsageMetric{Used: 2, Limit: 4, Percentage: 50}
+			},
+			wantStatus: SeverityOK,
+			wantMax:    50,
+		},
+		{
+			name: "ATTENTION no aparece en warnings",
+			setup: func(u *AllUsage) {
+				u.BlockStorage.Total = UsageMetric{Used: 150, Limit: 200, Percentage: 75}
+			},
+			wantStatus:   SeverityAttention,
+			wantMax:      75,
+			wantFindings: []string{"blockStorage.total"},
+		},
+		{
+			name: "load balancer y AMD también cuentan",
+			setup: func(u *AllUsage) {
+				u.LoadBalancer.Count = UsageMetric{Used: 1, Limit: 1, Percentage: 100}
+				u.Compute.AMD.Instances = UsageMetric{Used: 1, Limit: 2, Percentage: 50}
+			},
+			wantStatus:   SeverityCritical,
+			wantMax:      100,
+			wantFindings: []string{"loadBalancer.count"},
+			wantWarnings: 1,
+		},
+		{
+			name: "los hallazgos se ordenan por severidad",
+			setup: func(u *AllUsage) {
+				u.Compute.ARM.MemoryGB = UsageMetric{Used: 20, Limit: 24, Percentage: 83}
+				u.BlockStorage.Total = UsageMetric{Used: 190, Limit: 200, Percentage: 95}
+				u.PublicIPs = UsageMetric{Used: 1, Limit: 2, Percentage: 50}
+			},
+			wantStatus:   SeverityCritical,
+			wantMax:      95,
+			wantFindings: []string{"blockStorage.total", "compute.arm.memoryGB"},
+			wantWarnings: 2,
+		},
+		{
+			name: "un colector con error degrada el estado",
+			setup: func(u *AllUsage) {
+				u.PublicIPs = UsageMetric{Used: 0, Limit: 2, Percentage: 0}
+				u.Errors = []CollectorError{{Collector: "publicIPs", Message: "boom"}}
+			},
+			wantStatus:   SeverityDegraded,
+			wantWarnings: 1,
+		},
+		{
+			name: "un CRITICAL confirmado pesa más que DEGRADED",
+			setup: func(u *AllUsage) {
+				u.BlockStorage.Total = UsageMetric{Used: 190, Limit: 200, Percentage: 95}
+				u.Errors = []CollectorError{{Collector: "compute", Message: "boom"}}
+			},
+			wantStatus:   SeverityCritical,
+			wantMax:      95,
+			wantFindings: []string{"blockStorage.total"},
+			wantWarnings: 2,
+		},
+		{
+			name: "las métricas de un colector caído no se evalúan",
+			setup: func(u *AllUsage) {
+				u.BlockStorage.Total = UsageMetric{Used: 190, Limit: 200, Percentage: 95}
+				u.Errors = []CollectorError{{Collector: "blockStorage", Message: "boom"}}
+			},
+			wantStatus:   SeverityDegraded,
+			wantWarnings: 1,
+		},
+		{
+			name: "la proyección de peticiones de Object Storage solo avisa",
+			setup: func(u *AllUsage) {
+				u.ObjectStorage.Requests = UsageMetric{Used: 20000, Limit: 50000, Percentage: 40}
+				u.ObjectStorage.ProjectedRequests = UsageMetric{Used: 124000, Limit: 50000, Percentage: 248}
+			},
+			wantStatus:   SeverityWarning,
+			wantMax:      40,
+			wantFindings: []string{"objectStorage.projectedRequests"},
+			wantWarnings: 1,
+		},
+		{
+			name: "una Autonomous Database de pago es CRITICAL",
+			setup: func(u *AllUsage) {
+				u.Database.Paid = 1
+				u.Database.Databases = []DatabaseInfo{
+					{ID: "ocid1.autonomousdatabase.free", IsFreeTier: true},
+					{ID: "ocid1.autonomousdatabase.paid"},
+				}
+			},
+			wantStatus:   SeverityCritical,
+			wantFindings: []string{"database.paid"},
+			wantWarnings: 1,
+		},
+		{
+			name: "si fallan todos los colectores el estado es UNKNOWN",
+			setup: func(u *AllUsage) {
+				for _, name := range collectorNames {
+					u.Errors = append(u.Errors, CollectorError{Collector: name, Message: "401"})
+				}
+			},
+			wantStatus:   SeverityUnknown,
+			wantWarnings: len(collectorNames),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := &AllUsage{}
+			tt.setup(usage)
+
+			eval := evaluateUsage(usage)
+
+			if eval.Status != tt.wantStatus {
+				t.Errorf("Status = %s; want %s", eval.Status, tt.wantStatus)
+			}
+			if eval.MaxPercentage != tt.wantMax {
+				t.Errorf("MaxPercentage = %d; want %d", eval.MaxPercentage, tt.wantMax)
+			}
+			var got []string
+			for _, f := range eval.Findings {
+				got = append(got, f.Resource)
+			}
+			if !reflect.DeepEqual(got, tt.wantFindings) {
+				t.Errorf("findings = %v; want %v", got, tt.wantFindings)
+			}
+			if n := len(eval.Warnings()); n != tt.wantWarnings {
+				t.Errorf("len(Warnings()) = %d; want %d", n, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+// TestEvaluateUsageResourceIDs verifica que los hallazgos incluyen los OCIDs afectados
+func TestEvaluateUsageResourceIDs(t *testing.T) {
+	usage := &AllUsage{}
+	usage.Compute.ARM.OCPUs = UsageMetric{Used: 4, Limit: 4, Percentage: 100}
+	usage.Compute.Instances = []InstanceInfo{
+		{ID: "ocid1.instance.arm", Arch: "arm"},
+		{ID: "ocid1.instance.amd", Arch: "amd"},
+	}
+
+	eval := evaluateUsage(usage)
+	if len(eval.Findings) != 1 {
+		t.Fatalf("got %d findings; want 1", len(eval.Findings))
+	}
+	want := []string{"ocid1.instance.arm"}
+	if !reflect.DeepEqual(eval.Findings[0].ResourceIDs, want) {
+		t.Errorf("ResourceIDs = %v; want %v", eval.Findings[0].ResourceIDs, want)
+	}
+	if eval.Findings[0].Message != "ARM OCPUs at 100% (4 of 4, CRITICAL threshold 90%)" {
+		t.Errorf("Message = %q", eval.Findings[0].Message)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+// loadFixture lee un escenario de testdata
+func loadFixture(t *testing.T, name string) *ociScenario {
+	t.Helper()
+	scenario, err := loadScenario(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scenario
+}
+
+// useFakeBackend sustituye newOCIBackend por un escenario en memoria durante un test
+func useFakeBackend(t *testing.T, backend *scenarioBackend) {
+	t.Helper()
+	previous := newOCIBackend
+	newOCIBackend = func(common.ConfigurationProvider) ociBackend { return backend }
+	t.Cleanup(func() { newOCIBackend = previous })
+}
@@ -0,0 +1,357 @@
+// Package main - Este archivo guarda el histórico de uso en una base de datos local (bbolt)
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buckets de bbolt, uno por resolución
+// Los snapshots en crudo se agregan en rollups horarios y luego diarios al envejecer
+var (
+	historyRawBucket    = []byte("raw")
+	historyHourlyBucket = []byte("hourly")
+	historyDailyBucket  = []byte("daily")
+	// historyCapacityBucket guarda los informes de capacidad A1 (ver capacity.go)
+	historyCapacityBucket = []byte("capacity")
+)
+
+// HistoryStore persiste los snapshots de uso y los reduce con el tiempo
+type HistoryStore struct {
+	db              *bolt.DB
+	rawRetention    time.Duration
+	hourlyRetention time.Duration
+	dailyRetention  time.Duration
+}
+
+// historyRecord es lo que se guarda por cada punto: todas las métricas aplanadas
+// Samples indica cuántos snapshots se promediaron (1 para los datos en crudo)
+type historyRecord struct {
+	Metrics map[string]UsageMetric `json:"metrics"`
+	Samples int                    `json:"samples"`
+}
+
+// HistoryPoint es un punto de la serie temporal devuelta por /history
+type HistoryPoint struct {
+	Timestamp  string  `json:"timestamp"`
+	Resolution string  `json:"resolution"`
+	Used       float64 `json:"used"`
+	Limit      float64 `json:"limit"`
+	Percentage int     `json:"percentage"`
+	Samples    int     `json:"samples"`
+}
+
+// HistoryResponse es la respuesta del endpoint /history
+type HistoryResponse struct {
+	Metric    string         `json:"metric"`
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Points    []HistoryPoint `json:"points"`
+	Timestamp string         `json:"timestamp"`
+}
+
+// history es el almacén global (nil si el histórico no pudo abrirse)
+var history *HistoryStore
+
+// openHistoryStore abre (o crea) la base de datos de histórico
+func openHistoryStore(path string, rawRetention, hourlyRetention, dailyRetention time.Duration) (*HistoryStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening history database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{historyRawBucket, historyHourlyBucket, historyDailyBucket, historyCapacityBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error initializing history database: %w", err)
+	}
+
+	return &HistoryStore{
+		db:              db,
+		rawRetention:    rawRetention,
+		hourlyRetention: hourlyRetention,
+		dailyRetention:  dailyRetention,
+	}, nil
+}
+
+// Close cierra la base de datos
+func (h *HistoryStore) Close() error {
+	return h.db.Close()
+}
+
+// historyKey codifica un instante como clave ordenable (segundos Unix en big-endian)
+func historyKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(t.Unix()))
+	return key
+}
+
+// historyKeyTime decodifica una clave de historyKey
+func historyKeyTime(key []byte) time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint64(key)), 0).UTC()
+}
+
+// Record guarda un snapshot y aplica la retención y los rollups
+func (h *HistoryStore) Record(usage *AllUsage, at time.Time) error {
+	data, err := json.Marshal(historyRecord{
+		Metrics: reliableUsageMetrics(usage), // sin los colectores que fallaron
+		Samples: 1,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = h.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(historyRawBucket).Put(historyKey(at), data)
+	})
+	if err != nil {
+		return fmt.Errorf("error writing history: %w", err)
+	}
+
+	return h.Compact(at)
+}
+
+// Compact reduce los datos antiguos:
+// crudo más viejo que rawRetention → horario, horario más viejo que hourlyRetention → diario,
+// y borra los diarios más viejos que dailyRetention
+func (h *HistoryStore) Compact(now time.Time) error {
+	return h.db.Update(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(historyRawBucket)
+		hourly := tx.Bucket(historyHourlyBucket)
+		daily := tx.Bucket(historyDailyBucket)
+
+		err := rollupHistory(raw, hourly, now.Add(-h.rawRetention), func(t time.Time) time.Time {
+			return t.Truncate(time.Hour)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = rollupHistory(hourly, daily, now.Add(-h.hourlyRetention), func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		})
+		if err != nil {
+			return err
+		}
+
+		return deleteHistoryBefore(daily, now.Add(-h.dailyRetention))
+	})
+}
+
+// rollupHistory mueve los puntos de src anteriores a cutoff a dst, agrupados por period
+// Si dst ya tiene un punto para ese periodo se combinan con una media ponderada
+func rollupHistory(src, dst *bolt.Bucket, cutoff time.Time, period func(time.Time) time.Time) error {
+	groups := map[int64]historyRecord{}
+	var keys [][]byte
+
+	c := src.Cursor()
+	end := historyKey(cutoff)
+	for k, v := c.First(); k != nil && string(k) < string(end); k, v = c.Next() {
+		var record historyRecord
+		if err := json.Unmarshal(v, &record); err != nil {
+			return err
+		}
+		bucketTime := period(historyKeyTime(k)).Unix()
+		groups[bucketTime] = mergeHistoryRecords(groups[bucketTime], record)
+		keys = append(keys, append([]byte(nil), k...))
+	}
+
+	for bucketTime, record := range groups {
+		key := historyKey(time.Unix(bucketTime, 0))
+		if existing := dst.Get(key); existing != nil {
+			var previous historyRecord
+			if err := json.Unmarshal(existing, &previous); err != nil {
+				return err
+			}
+			record = mergeHistoryRecords(previous, record)
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		if err := dst.Put(key, data); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range keys {
+		if err := src.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteHistoryBefore borra los puntos anteriores a cutoff
+func deleteHistoryBefore(b *bolt.Bucket, cutoff time.Time) error {
+	var keys [][]byte
+	c := b.Cursor()
+	end := historyKey(cutoff)
+	for k, _ := c.First(); k != nil && string(k) < string(end); k, _ = c.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeHistoryRecords combina dos registros con una media ponderada por Samples
+func mergeHistoryRecords(a, b historyRecord) historyRecord {
+	if a.Samples == 0 {
+		return b
+	}
+	if b.Samples == 0 {
+		return a
+	}
+
+	total := a.Samples + b.Samples
+	merged := historyRecord{Metrics: map[string]UsageMetric{}, Samples: total}
+	for path, m := range a.Metrics {
+		merged.Metrics[path] = m
+	}
+	for path, m := range b.Metrics {
+		prev, ok := merged.Metrics[path]
+		if !ok {
+			merged.Metrics[path] = m
+			continue
+		}
+		wa, wb := float64(a.Samples), float64(b.Samples)
+		merged.Metrics[path] = UsageMetric{
+			Used:       (prev.Used*wa + m.Used*wb) / float64(total),
+			Limit:      m.Limit,
+			Percentage: int((float64(prev.Percentage)*wa + float64(m.Percentage)*wb) / float64(total)),
+		}
+	}
+	return merged
+}
+
+// Query devuelve la serie temporal de una métrica entre from y to, mezclando resoluciones
+func (h *HistoryStore) Query(metric string, from, to time.Time) ([]HistoryPoint, error) {
+	points := []HistoryPoint{}
+
+	err := h.db.View(func(tx *bolt.Tx) error {
+		tiers := []struct {
+			bucket     []byte
+			resolution string
+		}{
+			{historyDailyBucket, "daily"},
+			{historyHourlyBucket, "hourly"},
+			{historyRawBucket, "raw"},
+		}
+
+		for _, tier := range tiers {
+			c := tx.Bucket(tier.bucket).Cursor()
+			end := historyKey(to)
+			for k, v := c.Seek(historyKey(from)); k != nil && string(k) <= string(end); k, v = c.Next() {
+				var record historyRecord
+				if err := json.Unmarshal(v, &record); err != nil {
+					return err
+				}
+				m, ok := record.Metrics[metric]
+				if !ok {
+					continue
+				}
+				points = append(points, HistoryPoint{
+					Timestamp:  historyKeyTime(k).Format(time.RFC3339),
+					Resolution: tier.resolution,
+					Used:       m.Used,
+					Limit:      m.Limit,
+					Percentage: m.Percentage,
+					Samples:    record.Samples,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// RFC3339 en UTC se ordena igual como texto que como fecha
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Timestamp < points[j].Timestamp
+	})
+	return points, nil
+}
+
+// parseHistoryTime acepta una fecha RFC3339 o una duración relativa a now (ej. "24h")
+func parseHistoryTime(value string, now time.Time, defaultValue time.Time) (time.Time, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC3339 or a duration such as 24h", value)
+}
+
+// historyHandler maneja GET /history?metric=blockStorage.total&from=...&to=...
+func historyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if history == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "History store not available",
+		})
+		return
+	}
+
+	query := r.URL.Query()
+	metric := query.Get("metric")
+	if _, ok := flattenUsageMetrics(&AllUsage{})[metric]; !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":   fmt.Sprintf("Unknown metric %q", metric),
+			"metrics": usageMetricPaths(),
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	to, err := parseHistoryTime(query.Get("to"), now, now)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	from, err := parseHistoryTime(query.Get("from"), now, to.Add(-24*time.Hour))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	points, err := history.Query(metric, from, to)
+	if err != nil {
+		logger.Error().Err(err).Str("metric", metric).Msg("Error querying history")
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, HistoryResponse{
+		Metric:    metric,
+		From:      from.Format(time.RFC3339),
+		To:        to.Format(time.RFC3339),
+		Points:    points,
+		Timestamp: now.Format(time.RFC3339),
+	})
+}
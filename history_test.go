@@ -0,0 +1,85 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFlattenUsageMetrics verifica que las rutas siguen los tags json de AllUsage
+func TestFlattenUsageMetrics(t *testing.T) {
+	usage := &AllUsage{}
+	usage.Compute.ARM.OCPUs = UsageMetric{Used: 2, Limit: 4, Percentage: 50}
+	usage.BlockStorage.Total = UsageMetric{Used: 190, Limit: 200, Percentage: 95}
+
+	metrics := flattenUsageMetrics(usage)
+
+	tests := []struct {
+		path string
+		want float64
+	}{
+		{path: "compute.arm.ocpus", want: 2},
+		{path: "blockStorage.total", want: 190},
+		{path: "publicIPs", want: 0},
+		{path: "loadBalancer.count", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			m, ok := metrics[tt.path]
+			if !ok {
+				t.Fatalf("metric %q not found in %v", tt.path, usageMetricPaths())
+			}
+			if m.Used != tt.want {
+				t.Errorf("Used = %v; want %v", m.Used, tt.want)
+			}
+		})
+	}
+}
+
+// TestHistoryStoreRollups verifica el guardado, la consulta y el paso a rollups horarios y diarios
+func TestHistoryStoreRollups(t *testing.T) {
+	store, err := openHistoryStore(filepath.Join(t.TempDir(), "history.db"), 2*time.Hour, 48*time.Hour, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("openHistoryStore() error = %v", err)
+	}
+	defer store.Close()
+
+	base := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	record := func(at time.Time, used float64) {
+		usage := &AllUsage{}
+		usage.BlockStorage.Total = UsageMetric{Used: used, Limit: 200, Percentage: int(used / 2)}
+		if err := store.Record(usage, at); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	// Dos puntos en la misma hora de hace tres días, y uno reciente
+	record(base.Add(-72*time.Hour), 100)
+	record(base.Add(-72*time.Hour+10*time.Minute), 120)
+	record(base.Add(-5*time.Hour), 150)
+	record(base, 190)
+
+	points, err := store.Query("blockStorage.total", base.Add(-10*24*time.Hour), base)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	want := []struct {
+		resolution string
+		used       float64
+		samples    int
+	}{
+		{resolution: "daily", used: 110, samples: 2},
+		{resolution: "hourly", used: 150, samples: 1},
+		{resolution: "raw", used: 190, samples: 1},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points (%+v); want %d", len(points), points, len(want))
+	}
+	for i, w := range want {
+		if points[i].Resolution != w.resolution || points[i].Used != w.used || points[i].Samples != w.samples {
+			t.Errorf("point %d = %+v; want %+v", i, points[i], w)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+// Package main - Este archivo implementa el modo keepalive: cuando el watcher corre en la
+// propia instancia A1 vigilada, genera carga de CPU y memoria para que Oracle no la
+// considere inactiva (p95 de 7 días por debajo del 20%) y la reclame
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// keepaliveSlice es el ciclo de cada goroutine quemadora: trabaja load×slice y duerme el resto
+	keepaliveSlice = 100 * time.Millisecond
+	// keepaliveControlInterval es cada cuánto se mide el sistema y se ajusta la carga
+	keepaliveControlInterval = 5 * time.Second
+	// keepaliveChunkBytes es el tamaño de cada bloque del lastre de memoria
+	keepaliveChunkBytes = 64 << 20
+	// keepaliveMinDutyCycle es el mínimo útil: el p95 solo sube si más del 5% de las
+	// muestras de la semana superan el umbral
+	keepaliveMinDutyCycle = 0.05
+)
+
+// keepaliveConfig es la configuración del modo keepalive (variables KEEPALIVE_*)
+type keepaliveConfig struct {
+	CPUTarget    float64       // % de CPU total que debe tener la máquina mientras está activo
+	MemoryTarget float64       // % de memoria en uso que debe tener la máquina mientras está activo
+	DutyCycle    float64       // fracción de cada periodo en la que genera carga (0-1]
+	Period       time.Duration // longitud del ciclo de trabajo
+}
+
+// keepaliveConfigFromEnv lee la configuración del entorno
+// Devuelve false si KEEPALIVE_ENABLED no está activado
+func keepaliveConfigFromEnv() (keepaliveConfig, bool) {
+	enabled, _ := strconv.ParseBool(getEnv("KEEPALIVE_ENABLED", "false"))
+	if !enabled {
+		return keepaliveConfig{}, false
+	}
+	cfg := keepaliveConfig{
+		CPUTarget:    getEnvFloat("KEEPALIVE_CPU_TARGET", 25),
+		MemoryTarget: getEnvFloat("KEEPALIVE_MEMORY_TARGET", 25),
+		DutyCycle:    getEnvFloat("KEEPALIVE_DUTY_CYCLE", 0.25),
+		Period:       getEnvDuration("KEEPALIVE_PERIOD", time.Hour),
+	}
+	if cfg.CPUTarget > 100 {
+		cfg.CPUTarget = 100
+	}
+	if cfg.MemoryTarget > 90 {
+		// Siempre se deja margen para que el sistema no se quede sin memoria
+		cfg.MemoryTarget = 90
+	}
+	if cfg.DutyCycle > 1 {
+		cfg.DutyCycle = 1
+	}
+	if cfg.DutyCycle <= keepaliveMinDutyCycle {
+		logger.Warn().Float64("dutyCycle", cfg.DutyCycle).
+			Msg("KEEPALIVE_DUTY_CYCLE at or below 0.05 does not raise the 95th percentile")
+	}
+	return cfg, true
+}
+
+// KeepaliveStatus es la actividad del modo keepalive que se muestra en /usage
+type KeepaliveStatus struct {
+	Enabled             bool    `json:"enabled"`
+	Active              bool    `json:"active"` // dentro de la ventana del ciclo de trabajo
+	CPUTargetPercent    float64 `json:"cpuTargetPercent"`
+	MemoryTargetPercent float64 `json:"memoryTargetPercent"`
+	DutyCycle           float64 `json:"dutyCycle"`
+	Period              string  `json:"period"`
+	SystemCPUPercent    float64 `json:"systemCpuPercent"`    // CPU de toda la máquina en la última medida
+	GeneratedCPUPercent float64 `json:"generatedCpuPercent"` // parte de la CPU que genera el keepalive
+	SystemMemoryPercent float64 `json:"systemMemoryPercent"`
+	BallastMB           int     `json:"ballastMB"` // memoria reservada por el keepalive
+	BackedOff           bool    `json:"backedOff"` // la carga real ya supera el objetivo de CPU
+	UpdatedAt           string  `json:"updatedAt,omitempty"`
+	Error               string  `json:"error,omitempty"`
+}
+
+// keepalive es el generador activo, nil si el modo está desactivado
+var keepalive *keepaliveRunner
+
+// keepaliveRunner genera la carga y la ajusta a lo que ya consume el resto del sistema
+type keepaliveRunner struct {
+	cfg  keepaliveConfig
+	load atomic.Int64 // fracción de CPU total a quemar, en milésimas
+
+	// Lecturas del sistema; son campos para poder sustituirlas en los tests
+	readCPU    func() (cpuTimes, error)
+	readMemory func() (memoryInfo, error)
+
+	mu      sync.Mutex
+	lastCPU cpuTimes
+	haveCPU bool
+	ballast [][]byte
+	status  KeepaliveStatus
+	burners int
+}
+
+// newKeepaliveRunner crea el generador con las lecturas reales de /proc
+func newKeepaliveRunner(cfg keepaliveConfig) *keepaliveRunner {
+	return &keepaliveRunner{
+		cfg:        cfg,
+		readCPU:    readCPUTimes,
+		readMemory: readMemoryInfo,
+		burners:    runtime.NumCPU(),
+		status: KeepaliveStatus{
+			Enabled:             true,
+			CPUTargetPercent:    cfg.CPUTarget,
+			MemoryTargetPercent: cfg.MemoryTarget,
+			DutyCycle:           cfg.DutyCycle,
+			Period:              cfg.Period.String(),
+		},
+	}
+}
+
+// Start comprueba que se puede medir el sistema y lanza las goroutines de carga y control
+// Si el sistema no expone sus estadísticas (fuera de Linux) devuelve un error y no arranca
+func (k *keepaliveRunner) Start(stop <-chan struct{}) error {
+	first, err := k.readCPU()
+	if err != nil {
+		return fmt.Errorf("keepalive cannot read CPU statistics: %w", err)
+	}
+	if _, err := k.readMemory(); err != nil {
+		return fmt.Errorf("keepalive cannot read memory statistics: %w", err)
+	}
+	k.mu.Lock()
+	k.lastCPU, k.haveCPU = first, true
+	k.mu.Unlock()
+
+	// Una goroutine quemadora por CPU: cada una trabaja la misma fracción de su ciclo,
+	// así la carga total es load × 100% de la máquina
+	for i := 0; i < k.burners; i++ {
+		go k.burn(stop)
+	}
+
+	go func() {
+		k.step(time.Now())
+		ticker := time.NewTicker(keepaliveControlInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				k.step(now)
+			case <-stop:
+				k.load.Store(0)
+				k.mu.Lock()
+				k.setBallast(0)
+				k.mu.Unlock()
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// burn ocupa la CPU la fracción indicada de cada ciclo
+// En Go, un bucle vacío que consulta el reloj mantiene el núcleo ocupado sin
+// reservar memoria; el scheduler reparte las goroutines entre los núcleos
+func (k *keepaliveRunner) burn(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		busy := time.Duration(k.load.Load()) * keepaliveSlice / 1000
+		start := time.Now()
+		for time.Since(start) < busy {
+		}
+		time.Sleep(keepaliveSlice - busy)
+	}
+}
+
+// step mide el sistema y recalcula la carga de CPU y el lastre de memoria
+func (k *keepaliveRunner) step(now time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	generated := float64(k.load.Load()) / 10 // milésimas -> %
+	k.status.UpdatedAt = now.UTC().Format(time.RFC3339)
+	k.status.Error = ""
+	k.status.Active = inDutyWindow(now, k.cfg.Period, k.cfg.DutyCycle)
+
+	times, err := k.readCPU()
+	if err != nil {
+		k.fail(err)
+		return
+	}
+	if k.haveCPU {
+		k.status.SystemCPUPercent = cpuPercent(k.lastCPU, times)
+	}
+	k.lastCPU, k.haveCPU = times, true
+
+	mem, err := k.readMemory()
+	if err != nil {
+		k.fail(err)
+		return
+	}
+	k.status.SystemMemoryPercent = mem.usedPercent()
+
+	if !k.status.Active {
+		k.load.Store(0)
+		k.status.GeneratedCPUPercent = 0
+		k.status.BackedOff = false
+		k.setBallast(0)
+		return
+	}
+
+	load, backedOff := keepaliveCPULoad(k.cfg.CPUTarget, k.status.SystemCPUPercent, generated)
+	k.load.Store(int64(load * 10))
+	k.status.GeneratedCPUPercent = load
+	k.status.BackedOff = backedOff
+	k.setBallast(keepaliveBallastChunks(k.cfg.MemoryTarget, mem, k.ballastBytes()))
+}
+
+// fail detiene la carga cuando no se puede medir el sistema: sin medidas no hay back-off
+func (k *keepaliveRunner) fail(err error) {
+	logger.Warn().Err(err).Msg("Keepalive paused: cannot read system statistics")
+	k.status.Error = err.Error()
+	k.status.GeneratedCPUPercent = 0
+	k.load.Store(0)
+	k.setBallast(0)
+}
+
+// ballastBytes es la memoria que ocupa ahora el lastre
+func (k *keepaliveRunner) ballastBytes() uint64 {
+	return uint64(len(k.ballast)) * keepaliveChunkBytes
+}
+
+// setBallast ajusta el lastre al número de bloques indicado
+// Cada bloque nuevo se escribe página a página para que el sistema lo cuente como memoria en uso
+func (k *keepaliveRunner) setBallast(chunks int) {
+	for len(k.ballast) < chunks {
+		chunk := make([]byte, keepaliveChunkBytes)
+		for i := 0; i < len(chunk); i += 4096 {
+			chunk[i] = 1
+		}
+		k.ballast = append(k.ballast, chunk)
+	}
+	if len(k.ballast) > chunks {
+		for i := chunks; i < len(k.ballast); i++ {
+			k.ballast[i] = nil
+		}
+		k.ballast = k.ballast[:chunks]
+		// Devolver la memoria al sistema operativo en lugar de esperar al GC
+		debug.FreeOSMemory()
+	}
+	k.status.BallastMB = len(k.ballast) * (keepaliveChunkBytes >> 20)
+}
+
+// Status devuelve una copia del estado actual
+func (k *keepaliveRunner) Status() KeepaliveStatus {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.status
+}
+
+// keepaliveStatus es el estado que se incluye en /usage (nil si el modo está desactivado)
+func keepaliveStatus() *KeepaliveStatus {
+	if keepalive == nil {
+		return nil
+	}
+	status := keepalive.Status()
+	return &status
+}
+
+// inDutyWindow indica si now cae en la parte activa del ciclo de trabajo
+// Los ciclos se alinean con la época Unix, así la ventana es la misma tras un reinicio
+func inDutyWindow(now time.Time, period time.Duration, duty float64) bool {
+	if duty >= 1 || period <= 0 {
+		return true
+	}
+	offset := time.Duration(now.UnixNano() % int64(period))
+	return offset < time.Duration(float64(period)*duty)
+}
+
+// keepaliveCPULoad calcula el % de CPU total que debe generar el keepalive
+// El resto del sistema es lo medido menos lo que ya generábamos; si por sí solo llega al
+// objetivo, el keepalive se retira (backedOff) y deja la CPU a la carga real
+func keepaliveCPULoad(target, systemPercent, generatedPercent float64) (load float64, backedOff bool) {
+	other := systemPercent - generatedPercent
+	if other < 0 {
+		other = 0
+	}
+	if other >= target {
+		return 0, true
+	}
+	return target - other, false
+}
+
+// keepaliveBallastChunks calcula cuántos bloques de lastre hacen falta para llegar al
+// objetivo de memoria, descontando lo que ya usa el resto del sistema
+// Nunca toma más de la mitad de la memoria disponible en un solo paso
+func keepaliveBallastChunks(target float64, mem memoryInfo, ballast uint64) int {
+	if mem.Total == 0 {
+		return 0
+	}
+	used := mem.Total - mem.Available
+	other := uint64(0)
+	if used > ballast {
+		other = used - ballast
+	}
+	want := uint64(target / 100 * float64(mem.Total))
+	if want <= other {
+		return 0
+	}
+	need := want - other
+	if limit := ballast + mem.Available/2; need > limit {
+		need = limit
+	}
+	// Redondear hacia arriba para quedar justo por encima del objetivo
+	return int((need + keepaliveChunkBytes - 1) / keepaliveChunkBytes)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeepaliveCPULoad(t *testing.T) {
+	tests := []struct {
+		name              string
+		system, generated float64
+		wantLoad          float64
+		wantBackedOff     bool
+	}{
+		{name: "máquina parada", system: 0, generated: 0, wantLoad: 25},
+		{name: "solo nuestra carga", system: 25, generated: 25, wantLoad: 25},
+		{name: "carga real parcial", system: 35, generated: 25, wantLoad: 15},
+		{name: "carga real suficiente", system: 60, generated: 10, wantBackedOff: true},
+		{name: "medida por debajo de lo generado", system: 10, generated: 25, wantLoad: 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			load, backedOff := keepaliveCPULoad(25, tt.system, tt.generated)
+			if load != tt.wantLoad || backedOff != tt.wantBackedOff {
+				t.Errorf("keepaliveCPULoad = (%v, %v), want (%v, %v)", load, backedOff, tt.wantLoad, tt.wantBackedOff)
+			}
+		})
+	}
+}
+
+func TestInDutyWindow(t *testing.T) {
+	hour := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		now  time.Time
+		duty float64
+		want bool
+	}{
+		{name: "inicio del periodo", now: hour, duty: 0.25, want: true},
+		{name: "dentro de la ventana", now: hour.Add(14 * time.Minute), duty: 0.25, want: true},
+		{name: "fuera de la ventana", now: hour.Add(15 * time.Minute), duty: 0.25, want: false},
+		{name: "ciclo completo", now: hour.Add(59 * time.Minute), duty: 1, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inDutyWindow(tt.now, time.Hour, tt.duty); got != tt.want {
+				t.Errorf("inDutyWindow = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeepaliveBallastChunks(t *testing.T) {
+	const gb = 1024 << 20
+	tests := []struct {
+		name    string
+		target  float64
+		mem     memoryInfo
+		ballast uint64
+		want    int
+	}{
+		{name: "sube hasta el objetivo", target: 25, mem: memoryInfo{Total: 4 * gb, Available: 3584 << 20}, want: 8},
+		{name: "mantiene el lastre actual", target: 25, mem: memoryInfo{Total: 4 * gb, Available: 3 * gb}, ballast: 8 * keepaliveChunkBytes, want: 8},
+		{name: "la carga real basta", target: 25, mem: memoryInfo{Total: 4 * gb, Available: 2 * gb}, want: 0},
+		{name: "limitado por la memoria disponible", target: 90, mem: memoryInfo{Total: 4 * gb, Available: gb}, want: 8},
+		{name: "sin datos de memoria", target: 25, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keepaliveBallastChunks(tt.target, tt.mem, tt.ballast); got != tt.want {
+				t.Errorf("keepaliveBallastChunks = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeepaliveStep(t *testing.T) {
+	cfg := keepaliveConfig{CPUTarget: 25, MemoryTarget: 10, DutyCycle: 0.5, Period: time.Hour}
+	cpu := cpuTimes{Idle: 0, Total: 0}
+	var cpuErr error
+	k := newKeepaliveRunner(cfg)
+	k.readCPU = func() (cpuTimes, error) { return cpu, cpuErr }
+	k.readMemory = func() (memoryInfo, error) {
+		// 50% en uso: por encima del objetivo de memoria, no hace falta lastre
+		return memoryInfo{Total: 1000, Available: 500}, nil
+	}
+	k.lastCPU, k.haveCPU = cpu, true
+	active := time.Date(2026, 10, 18, 12, 10, 0, 0, time.UTC)
+
+	// Máquina al 10%: el keepalive genera el resto hasta el 25%
+	cpu = cpuTimes{Idle: 900, Total: 1000}
+	k.step(active)
+	status := k.Status()
+	if !status.Active || status.GeneratedCPUPercent != 15 || status.BackedOff || status.BallastMB != 0 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if got := k.load.Load(); got != 150 {
+		t.Errorf("load = %d‰, want 150‰", got)
+	}
+
+	// Máquina al 80% con nuestro 15%: hay carga real, se retira
+	cpu = cpuTimes{Idle: 1100, Total: 2000}
+	k.step(active.Add(time.Minute))
+	if status := k.Status(); !status.BackedOff || status.GeneratedCPUPercent != 0 {
+		t.Errorf("expected back-off, got %+v", status)
+	}
+
+	// Fuera de la ventana del ciclo de trabajo no genera carga
+	cpu = cpuTimes{Idle: 2000, Total: 3000}
+	k.step(active.Add(30 * time.Minute))
+	if status := k.Status(); status.Active || k.load.Load() != 0 {
+		t.Errorf("expected inactive, got %+v (load %d)", status, k.load.Load())
+	}
+
+	// Sin estadísticas del sistema se para y lo informa
+	k.load.Store(100)
+	cpuErr = errors.New("boom")
+	k.step(active)
+	if status := k.Status(); status.Error == "" || k.load.Load() != 0 {
+		t.Errorf("expected error and no load, got %+v", status)
+	}
+}
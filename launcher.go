@@ -0,0 +1,600 @@
+// Package main - Este archivo implementa el lanzador de instancias A1: reintenta
+// LaunchInstance en todos los dominios de disponibilidad y de fallo hasta que aparece
+// capacidad ("Out of host capacity"), sin salirse nunca de la Free Tier
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultLaunchShape es la shape Always Free de ARM
+	defaultLaunchShape = "VM.Standard.A1.Flex"
+	// minBootVolumeGB es el tamaño mínimo de un boot volume en OCI
+	minBootVolumeGB = 50
+)
+
+// errLaunchHeadroom indica que la instancia no cabe en lo que queda de Free Tier
+var errLaunchHeadroom = errors.New("launch would exceed the Always Free headroom")
+
+// errLaunchUnknown indica que el último intento terminó sin saber si se creó la instancia
+var errLaunchUnknown = errors.New("launch outcome unknown")
+
+// LaunchTemplate describe la instancia a crear (fichero YAML o JSON)
+type LaunchTemplate struct {
+	DisplayName      string  `yaml:"displayName" json:"displayName"`
+	CompartmentID    string  `yaml:"compartmentId" json:"compartmentId"` // por defecto OCI_COMPARTMENT_ID
+	Shape            string  `yaml:"shape" json:"shape"`                 // por defecto VM.Standard.A1.Flex
+	OCPUs            float64 `yaml:"ocpus" json:"ocpus"`
+	MemoryGB         float64 `yaml:"memoryGB" json:"memoryGB"`
+	ImageID          string  `yaml:"imageId" json:"imageId"`
+	SubnetID         string  `yaml:"subnetId" json:"subnetId"`
+	SSHPublicKey     string  `yaml:"sshPublicKey" json:"sshPublicKey"`
+	SSHPublicKeyFile string  `yaml:"sshPublicKeyFile" json:"sshPublicKeyFile"` // alternativa a sshPublicKey
+	BootVolumeGB     int     `yaml:"bootVolumeGB" json:"bootVolumeGB"`         // por defecto 50
+	AssignPublicIP   *bool   `yaml:"assignPublicIp" json:"assignPublicIp"`     // por defecto true
+	// AvailabilityDomains limita los ADs a probar ("AD-1" o el nombre completo); vacío = todos
+	AvailabilityDomains []string `yaml:"availabilityDomains" json:"availabilityDomains"`
+}
+
+// loadLaunchTemplate lee y valida una plantilla
+// En Go, YAML es un superconjunto de JSON: yaml.v3 lee los dos formatos
+func loadLaunchTemplate(path string) (LaunchTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LaunchTemplate{}, err
+	}
+	var template LaunchTemplate
+	if err := yaml.Unmarshal(data, &template); err != nil {
+		return LaunchTemplate{}, fmt.Errorf("invalid launch template %s: %w", path, err)
+	}
+	if template.SSHPublicKey == "" && template.SSHPublicKeyFile != "" {
+		key, err := os.ReadFile(expandHome(template.SSHPublicKeyFile))
+		if err != nil {
+			return LaunchTemplate{}, fmt.Errorf("cannot read SSH public key: %w", err)
+		}
+		template.SSHPublicKey = strings.TrimSpace(string(key))
+	}
+	if template.CompartmentID == "" {
+		template.CompartmentID = getCompartmentID()
+	}
+	if err := template.normalize(); err != nil {
+		return LaunchTemplate{}, fmt.Errorf("invalid launch template %s: %w", path, err)
+	}
+	return template, nil
+}
+
+// expandHome sustituye un "~/" inicial por el directorio del usuario, como haría la shell
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// normalize aplica los valores por defecto y comprueba los campos obligatorios
+func (t *LaunchTemplate) normalize() error {
+	if t.Shape == "" {
+		t.Shape = defaultLaunchShape
+	}
+	if t.BootVolumeGB == 0 {
+		t.BootVolumeGB = minBootVolumeGB
+	}
+	if t.DisplayName == "" {
+		t.DisplayName = "arm-watcher-a1"
+	}
+
+	var problems []string
+	// Solo la VM flexible es Always Free: una bare metal A1 también contiene "A1" y se factura
+	if t.Shape != defaultLaunchShape {
+		problems = append(problems, fmt.Sprintf("shape %q is not an A1 shape (only %s is Always Free)", t.Shape, defaultLaunchShape))
+	}
+	if t.OCPUs <= 0 {
+		problems = append(problems, "ocpus must be positive")
+	}
+	if t.MemoryGB <= 0 {
+		problems = append(problems, "memoryGB must be positive")
+	}
+	if t.BootVolumeGB < minBootVolumeGB {
+		problems = append(problems, fmt.Sprintf("bootVolumeGB must be at least %d", minBootVolumeGB))
+	}
+	for field, value := range map[string]string{
+		"compartmentId": t.CompartmentID,
+		"imageId":       t.ImageID,
+		"subnetId":      t.SubnetID,
+		"sshPublicKey":  t.SSHPublicKey,
+	} {
+		if value == "" {
+			problems = append(problems, field+" is required")
+		}
+	}
+	if len(problems) > 0 {
+		// El orden de un map no es fijo: se ordena para que el mensaje sea estable
+		sort.Strings(problems)
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkLaunchHeadroom rechaza la plantilla si, sumada al uso actual, supera los límites
+// Always Free de ARM (OCPUs, memoria, instancias) o del almacenamiento en bloque
+// Usa las mismas cuentas que el planificador (computeHeadroom y applyChange)
+func checkLaunchHeadroom(t LaunchTemplate, usage *AllUsage, limits FreeTierLimits) error {
+	// Sin datos fiables de uso no se puede garantizar que la instancia sea gratis
+	if usage.Compute.Error != "" || usage.Compute.Truncated {
+		return fmt.Errorf("%w: cannot verify ARM usage (compute collector incomplete)", errLaunchHeadroom)
+	}
+	if usage.BlockStorage.Error != "" || usage.BlockStorage.Truncated {
+		return fmt.Errorf("%w: cannot verify block storage usage (collector incomplete)", errLaunchHeadroom)
+	}
+
+	headroom := computeHeadroom(usage.Compute, usage.BlockStorage, limits)
+	change := PlanChange{
+		Action:       "add",
+		Type:         "instance",
+		Shape:        t.Shape,
+		OCPUs:        t.OCPUs,
+		MemoryGB:     t.MemoryGB,
+		BootVolumeGB: t.BootVolumeGB,
+	}
+	if err := applyChange(&headroom, change, usage.Compute, usage.BlockStorage); err != nil {
+		return fmt.Errorf("%w: %v", errLaunchHeadroom, err)
+	}
+	if problems := headroom.exceeded(); len(problems) > 0 {
+		return fmt.Errorf("%w: %s", errLaunchHeadroom, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// launchPlacement es un destino donde probar el lanzamiento
+type launchPlacement struct {
+	AvailabilityDomain string
+	FaultDomain        string // vacío: que OCI elija
+}
+
+// String devuelve el destino en formato "AD/FD" para los logs
+func (p launchPlacement) String() string {
+	if p.FaultDomain == "" {
+		return p.AvailabilityDomain
+	}
+	return p.AvailabilityDomain + "/" + p.FaultDomain
+}
+
+// listPlacements devuelve todas las combinaciones AD × FD del tenancy
+// only filtra los ADs por nombre completo o por sufijo ("AD-1")
+func listPlacements(ctx context.Context, client identityAPI, tenancyID string, only []string) ([]launchPlacement, error) {
+	response, err := client.ListAvailabilityDomains(ctx, identity.ListAvailabilityDomainsRequest{
+		CompartmentId: common.String(tenancyID),
+	})
+	if err != nil {
+		return nil, ociError("identity", "ListAvailabilityDomains", err)
+	}
+
+	var placements []launchPlacement
+	for _, ad := range response.Items {
+		if ad.Name == nil || !matchesAvailabilityDomain(*ad.Name, only) {
+			continue
+		}
+		fds, err := client.ListFaultDomains(ctx, identity.ListFaultDomainsRequest{
+			CompartmentId:      common.String(tenancyID),
+			AvailabilityDomain: ad.Name,
+		})
+		if err != nil {
+			return nil, ociError("identity", "ListFaultDomains", err)
+		}
+		if len(fds.Items) == 0 {
+			placements = append(placements, launchPlacement{AvailabilityDomain: *ad.Name})
+		}
+		for _, fd := range fds.Items {
+			if fd.Name != nil {
+				placements = append(placements, launchPlacement{AvailabilityDomain: *ad.Name, FaultDomain: *fd.Name})
+			}
+		}
+	}
+	if len(placements) == 0 {
+		return nil, fmt.Errorf("no availability domain matches %v", only)
+	}
+	return placements, nil
+}
+
+// matchesAvailabilityDomain indica si un AD ("Uocm:EU-MADRID-1-AD-1") está en la lista
+func matchesAvailabilityDomain(name string, only []string) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, want := range only {
+		if strings.EqualFold(name, want) || strings.HasSuffix(strings.ToUpper(name), "-"+strings.ToUpper(want)) {
+			return true
+		}
+	}
+	return false
+}
+
+// launchConfig controla los reintentos del lanzador
+type launchConfig struct {
+	Interval    time.Duration // espera tras la primera ronda sin capacidad
+	MaxInterval time.Duration // tope de la espera exponencial
+	MaxAttempts int           // 0 = sin límite
+}
+
+// currentLaunchConfig lee LAUNCH_RETRY_INTERVAL y LAUNCH_RETRY_MAX_INTERVAL
+func currentLaunchConfig() launchConfig {
+	return launchConfig{
+		Interval:    getEnvDuration("LAUNCH_RETRY_INTERVAL", time.Minute),
+		MaxInterval: getEnvDuration("LAUNCH_RETRY_MAX_INTERVAL", 10*time.Minute),
+	}
+}
+
+// launchBackoff calcula la espera tras la ronda indicada (empezando en 0)
+// Dobla el intervalo en cada ronda hasta el tope y aplica "equal jitter": la espera real
+// está entre la mitad y el total, para no reintentar al mismo ritmo que otros lanzadores
+func launchBackoff(round int, cfg launchConfig, random float64) time.Duration {
+	d := cfg.Interval
+	for i := 0; i < round && d < cfg.MaxInterval; i++ {
+		d *= 2
+	}
+	if d > cfg.MaxInterval {
+		d = cfg.MaxInterval
+	}
+	return d/2 + time.Duration(random*float64(d/2))
+}
+
+// retryableLaunchError indica si merece la pena reintentar tras un error de LaunchInstance
+// "Out of host capacity" llega como 500 InternalError; 429 y 5xx son transitorios.
+// Los 4xx restantes (parámetros, permisos, LimitExceeded) no se arreglan reintentando
+func retryableLaunchError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	failure, ok := common.IsServiceError(err)
+	if !ok {
+		// Errores de red o timeouts de una llamada: se reintentan
+		return true
+	}
+	if strings.Contains(strings.ToLower(failure.GetMessage()), "out of host capacity") {
+		return true
+	}
+	switch failure.GetHTTPStatusCode() {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// ambiguousLaunchError indica si no se sabe si OCI llegó a crear la instancia
+// Un error de red, un timeout o un 5xx de un balanceador o gateway puede llegar después de
+// que OCI aceptara la petición. "Out of host capacity" (500) y los 4xx, en cambio, son
+// respuestas del propio servicio: no se creó nada
+func ambiguousLaunchError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	failure, ok := common.IsServiceError(err)
+	if !ok {
+		return true
+	}
+	if strings.Contains(strings.ToLower(failure.GetMessage()), "out of host capacity") {
+		return false
+	}
+	return failure.GetHTTPStatusCode() >= http.StatusInternalServerError
+}
+
+// isThrottled indica si OCI pide bajar el ritmo (429): se corta la ronda y se espera
+func isThrottled(err error) bool {
+	failure, ok := common.IsServiceError(err)
+	return ok && failure.GetHTTPStatusCode() == http.StatusTooManyRequests
+}
+
+// instanceLauncher reintenta LaunchInstance hasta crear la instancia
+type instanceLauncher struct {
+	template LaunchTemplate
+	cfg      launchConfig
+	backend  ociBackend
+	tenancy  string
+
+	// Dependencias sustituibles en los tests
+	usage  func(ctx context.Context) (*AllUsage, error) // uso actual para comprobar el margen
+	notify func(n Notification)                         // puede ser nil
+	sleep  func(ctx context.Context, d time.Duration) error
+	random func() float64
+}
+
+// newInstanceLauncher crea un lanzador con las dependencias reales
+func newInstanceLauncher(template LaunchTemplate, cfg launchConfig, backend ociBackend, tenancyID string) *instanceLauncher {
+	return &instanceLauncher{
+		template: template,
+		cfg:      cfg,
+		backend:  backend,
+		tenancy:  tenancyID,
+		usage:    getLaunchUsage,
+		sleep:    sleepContext,
+		random:   rand.Float64,
+	}
+}
+
+// getLaunchUsage recolecta solo lo que lee checkLaunchHeadroom: compute y block storage
+// de los compartimentos de OCI_COMPARTMENT_SCAN. Se llama en cada ronda: getOCIUsage
+// lanzaría también las consultas de Monitoring, los GetBucket y el resto de colectores,
+// gastando cuota de la API sin cambiar la decisión
+func getLaunchUsage(ctx context.Context) (*AllUsage, error) {
+	provider, err := createConfigProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	pagination := currentPagination()
+	timeouts := currentTimeouts()
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Global)
+	defer cancel()
+
+	backend := newOCIBackend(provider)
+	compartments, err := resolveCompartments(ctx, backend, pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]*AllUsage, len(compartments))
+	for i, compartment := range compartments {
+		part := &AllUsage{}
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			computeCtx, cancel := context.WithTimeout(ctx, timeouts.For("compute"))
+			defer cancel()
+			// El error queda en Compute.Error: checkLaunchHeadroom se niega a lanzar sin datos
+			part.Compute, _ = getComputeUsage(computeCtx, backend, compartment.ID, pagination)
+		}()
+		go func() {
+			defer wg.Done()
+			storageCtx, cancel := context.WithTimeout(ctx, timeouts.For("blockStorage"))
+			defer cancel()
+			part.BlockStorage, _ = getBlockStorageUsage(storageCtx, backend, compartment.ID, pagination)
+		}()
+		wg.Wait()
+		parts[i] = part
+	}
+	return mergeUsage(parts...), nil
+}
+
+// sleepContext espera d o hasta que se cancele ctx
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run prueba todos los destinos en rondas hasta que uno tiene capacidad
+// Antes de cada ronda vuelve a comprobar el margen: otra instancia creada mientras tanto
+// podría hacer que esta ya no fuera gratis
+func (l *instanceLauncher) Run(ctx context.Context) (*core.Instance, error) {
+	compute, err := l.backend.Compute()
+	if err != nil {
+		return nil, err
+	}
+	identityClient, err := l.backend.Identity()
+	if err != nil {
+		return nil, err
+	}
+	placements, err := listPlacements(ctx, identityClient, l.tenancy, l.template.AvailabilityDomains)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := 0
+	// pending es la petición que terminó con un error ambiguo; se repite tal cual, con el
+	// mismo opc-retry-token, para que OCI devuelva la instancia si ya la había creado
+	var pending *launchAttempt
+	for round := 0; ; round++ {
+		targets := make([]launchAttempt, 0, len(placements))
+		if pending != nil {
+			// La petición ya pasó la comprobación de margen en su ronda
+			targets = append(targets, *pending)
+			pending = nil
+		} else {
+			usage, err := l.usage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("cannot collect usage to check headroom: %w", err)
+			}
+			if err := checkLaunchHeadroom(l.template, usage, currentLimits()); err != nil {
+				return nil, err
+			}
+			for _, placement := range placements {
+				targets = append(targets, launchAttempt{placement: placement, request: l.request(placement)})
+			}
+		}
+
+		for _, target := range targets {
+			attempts++
+			response, err := compute.LaunchInstance(ctx, target.request)
+			if err == nil {
+				instance := response.Instance
+				logger.Info().
+					Str("instance_id", stringValue(instance.Id)).
+					Str("placement", target.placement.String()).
+					Int("attempts", attempts).
+					Msg("🚀 Instance launched")
+				if l.notify != nil {
+					l.notify(launchNotification(l.template, instance, target.placement, attempts))
+				}
+				return &instance, nil
+			}
+			if !retryableLaunchError(err) {
+				return nil, ociError("core", "LaunchInstance", err)
+			}
+			lastAttempt := l.cfg.MaxAttempts > 0 && attempts >= l.cfg.MaxAttempts
+			if ambiguousLaunchError(err) {
+				token := stringValue(target.request.OpcRetryToken)
+				if lastAttempt {
+					// La instancia puede existir: el token permite comprobarlo o repetir la petición
+					return nil, fmt.Errorf("%w after %d attempts in %s (opc-retry-token %s): %v",
+						errLaunchUnknown, attempts, target.placement, token, err)
+				}
+				logger.Warn().
+					Err(err).
+					Str("placement", target.placement.String()).
+					Str("opc_retry_token", token).
+					Int("attempt", attempts).
+					Msg("Launch outcome unknown, retrying the same request")
+				// Probar otro destino podría crear una segunda instancia
+				target := target
+				pending = &target
+				break
+			}
+			logger.Warn().
+				Err(err).
+				Str("placement", target.placement.String()).
+				Int("attempt", attempts).
+				Msg("No capacity, trying next placement")
+			if lastAttempt {
+				return nil, fmt.Errorf("no capacity after %d attempts: %w", attempts, err)
+			}
+			if isThrottled(err) {
+				break
+			}
+		}
+
+		delay := launchBackoff(round, l.cfg, l.random())
+		logger.Info().Dur("delay", delay).Int("attempts", attempts).Msg("Waiting before next launch round")
+		if err := l.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// launchAttempt es una petición de LaunchInstance lista para enviar a un destino
+type launchAttempt struct {
+	placement launchPlacement
+	request   core.LaunchInstanceRequest
+}
+
+// request construye la petición de LaunchInstance para un destino
+func (l *instanceLauncher) request(placement launchPlacement) core.LaunchInstanceRequest {
+	t := l.template
+	assignPublicIP := true
+	if t.AssignPublicIP != nil {
+		assignPublicIP = *t.AssignPublicIP
+	}
+	details := core.LaunchInstanceDetails{
+		AvailabilityDomain: common.String(placement.AvailabilityDomain),
+		CompartmentId:      common.String(t.CompartmentID),
+		Shape:              common.String(t.Shape),
+		DisplayName:        common.String(t.DisplayName),
+		ShapeConfig: &core.LaunchInstanceShapeConfigDetails{
+			Ocpus:       common.Float32(float32(t.OCPUs)),
+			MemoryInGBs: common.Float32(float32(t.MemoryGB)),
+		},
+		SourceDetails: core.InstanceSourceViaImageDetails{
+			ImageId:             common.String(t.ImageID),
+			BootVolumeSizeInGBs: common.Int64(int64(t.BootVolumeGB)),
+		},
+		CreateVnicDetails: &core.CreateVnicDetails{
+			SubnetId:       common.String(t.SubnetID),
+			AssignPublicIp: common.Bool(assignPublicIP),
+		},
+		Metadata: map[string]string{"ssh_authorized_keys": t.SSHPublicKey},
+	}
+	if placement.FaultDomain != "" {
+		details.FaultDomain = common.String(placement.FaultDomain)
+	}
+	// El SDK genera un token nuevo en cada llamada; fijarlo permite repetir la misma petición
+	return core.LaunchInstanceRequest{LaunchInstanceDetails: details, OpcRetryToken: common.String(common.RetryToken())}
+}
+
+// launchNotification es el aviso que se envía al crear la instancia
+func launchNotification(t LaunchTemplate, instance core.Instance, placement launchPlacement, attempts int) Notification {
+	return Notification{
+		Status:  SeverityOK,
+		Event:   "instance.launched",
+		Summary: fmt.Sprintf("🚀 A1 instance %s launched", t.DisplayName),
+		Warnings: []string{
+			"Instance: " + stringValue(instance.Id),
+			"Placement: " + placement.String(),
+			fmt.Sprintf("Shape: %s (%g OCPUs, %g GB)", t.Shape, t.OCPUs, t.MemoryGB),
+			fmt.Sprintf("Attempts: %d", attempts),
+		},
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// runLaunchCommand crea una instancia A1 a partir de una plantilla, reintentando hasta
+// que haya capacidad; Ctrl+C lo detiene. Sale con 2 si la plantilla no cabe en la Free Tier
+func runLaunchCommand(args []string, stdout io.Writer) int {
+	flags := flag.NewFlagSet("launch", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	templatePath := flags.String("template", "", "instance template (YAML or JSON)")
+	maxAttempts := flags.Int("max-attempts", 0, "give up after this many LaunchInstance calls (0 = never)")
+	if err := flags.Parse(args); err != nil {
+		return exitUnknown
+	}
+	if *templatePath == "" {
+		fmt.Fprintln(stdout, "ERROR: -template is required")
+		return exitUnknown
+	}
+	if !isConfigured() {
+		fmt.Fprintln(stdout, "NOT_CONFIGURED: OCI credentials not configured")
+		return exitUnknown
+	}
+
+	template, err := loadLaunchTemplate(*templatePath)
+	if err != nil {
+		fmt.Fprintf(stdout, "ERROR: %v\n", err)
+		return exitUnknown
+	}
+	provider, err := createConfigProvider()
+	if err != nil {
+		fmt.Fprintf(stdout, "ERROR: %v\n", err)
+		return exitUnknown
+	}
+
+	cfg := currentLaunchConfig()
+	cfg.MaxAttempts = *maxAttempts
+	launcher := newInstanceLauncher(template, cfg, newOCIBackend(provider), getTenancyID())
+	if notifiers := notifiersFromEnv(); len(notifiers) > 0 {
+		launcher.notify = newNotificationManager(notifiers, 0).Dispatch
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	instance, err := launcher.Run(ctx)
+	switch {
+	case errors.Is(err, errLaunchHeadroom):
+		fmt.Fprintf(stdout, "REFUSED: %v\n", err)
+		return exitCritical
+	case err != nil:
+		fmt.Fprintf(stdout, "ERROR: %v\n", err)
+		return exitUnknown
+	}
+	fmt.Fprintf(stdout, "LAUNCHED: %s (%s) in %s\n", stringValue(instance.Id), stringValue(instance.DisplayName), stringValue(instance.AvailabilityDomain))
+	return exitOK
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// validTemplate es una plantilla A1 completa de 2 OCPUs y 12 GB
+func validTemplate() LaunchTemplate {
+	t := LaunchTemplate{
+		CompartmentID: testCompartment,
+		OCPUs:         2,
+		MemoryGB:      12,
+		ImageID:       "ocid1.image.oc1..test",
+		SubnetID:      "ocid1.subnet.oc1..test",
+		SSHPublicKey:  "ssh-ed25519 AAAA test",
+	}
+	if err := t.normalize(); err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// armUsage es un uso con ocpus/memoria ARM y GB de bloque ya ocupados
+func armUsage(ocpus, memoryGB float64, instances int, blockGB float64) *AllUsage {
+	usage := &AllUsage{}
+	usage.Compute.ARM.OCPUs.Used = ocpus
+	usage.Compute.ARM.MemoryGB.Used = memoryGB
+	usage.Compute.ARM.Instances = instances
+	usage.BlockStorage.Total.Used = blockGB
+	return usage
+}
+
+func TestLaunchTemplateNormalize(t *testing.T) {
+	template := validTemplate()
+	if template.Shape != defaultLaunchShape || template.BootVolumeGB != minBootVolumeGB {
+		t.Errorf("defaults not applied: %+v", template)
+	}
+
+	invalid := LaunchTemplate{Shape: "VM.Standard.E4.Flex", OCPUs: 1, MemoryGB: 6, BootVolumeGB: 20}
+	err := invalid.normalize()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"not an A1 shape", "bootVolumeGB", "imageId is required", "subnetId is required"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	// Una bare metal A1 contiene "A1" pero no es Always Free
+	bareMetal := validTemplate()
+	bareMetal.Shape = "BM.Standard.A1.160"
+	if err := bareMetal.normalize(); err == nil || !strings.Contains(err.Error(), "not an A1 shape") {
+		t.Errorf("BM.Standard.A1.160 accepted: %v", err)
+	}
+}
+
+func TestCheckLaunchHeadroom(t *testing.T) {
+	limits := currentLimits()
+	tests := []struct {
+		name    string
+		usage   *AllUsage
+		limits  func(l *FreeTierLimits) // ajusta los límites del caso
+		wantErr string
+	}{
+		{name: "cabe", usage: armUsage(2, 12, 1, 50)},
+		{name: "justo en el límite", usage: armUsage(2, 12, 3, 150)},
+		{name: "sin OCPUs", usage: armUsage(3, 6, 1, 50), wantErr: "compute.arm.ocpus exceeded by 1"},
+		{name: "sin memoria", usage: armUsage(1, 20, 1, 50), wantErr: "compute.arm.memoryGB exceeded by 8"},
+		{name: "sin instancias", usage: armUsage(0, 0, 4, 0), wantErr: "compute.arm.instances exceeded by 1"},
+		{
+			name:    "límite de instancias a 0",
+			usage:   armUsage(0, 0, 0, 0),
+			limits:  func(l *FreeTierLimits) { l.Compute.ARM.MaxInstances = 0 },
+			wantErr: "compute.arm.instances exceeded by 1",
+		},
+		{name: "sin almacenamiento", usage: armUsage(0, 0, 0, 180), wantErr: "blockStorage.total exceeded by 30 GB"},
+		{
+			name: "compute incompleto",
+			usage: func() *AllUsage {
+				u := armUsage(0, 0, 0, 0)
+				u.Compute.Error = "boom"
+				return u
+			}(),
+			wantErr: "cannot verify ARM usage",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caseLimits := limits
+			if tt.limits != nil {
+				tt.limits(&caseLimits)
+			}
+			err := checkLaunchHeadroom(validTemplate(), tt.usage, caseLimits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, errLaunchHeadroom) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLaunchBackoff(t *testing.T) {
+	cfg := launchConfig{Interval: time.Minute, MaxInterval: 10 * time.Minute}
+	tests := []struct {
+		round  int
+		random float64
+		want   time.Duration
+	}{
+		{round: 0, random: 0, want: 30 * time.Second},
+		{round: 0, random: 1, want: time.Minute},
+		{round: 2, random: 1, want: 4 * time.Minute},
+		{round: 10, random: 1, want: 10 * time.Minute},
+		{round: 10, random: 0.5, want: 7*time.Minute + 30*time.Second},
+	}
+	for _, tt := range tests {
+		if got := launchBackoff(tt.round, cfg, tt.random); got != tt.want {
+			t.Errorf("launchBackoff(%d, %v) = %v, want %v", tt.round, tt.random, got, tt.want)
+		}
+	}
+}
+
+func TestRetryableLaunchError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "sin capacidad", err: scenarioError{Status: http.StatusInternalServerError, Code: "InternalError", Message: "Out of host capacity."}, want: true},
+		{name: "throttling", err: scenarioError{Status: http.StatusTooManyRequests, Code: "TooManyRequests"}, want: true},
+		{name: "límite de servicio", err: scenarioError{Status: http.StatusBadRequest, Code: "LimitExceeded", Message: "limit exceeded"}},
+		{name: "imagen inexistente", err: scenarioError{Status: http.StatusNotFound, Code: "NotAuthorizedOrNotFound"}},
+		{name: "error de red", err: errors.New("connection reset"), want: true},
+		{name: "cancelado", err: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableLaunchError(tt.err); got != tt.want {
+				t.Errorf("retryableLaunchError = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmbiguousLaunchError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "sin capacidad", err: scenarioError{Status: http.StatusInternalServerError, Code: "InternalError", Message: "Out of host capacity."}},
+		{name: "gateway caído", err: scenarioError{Status: http.StatusBadGateway, Code: "BadGateway", Message: "bad gateway"}, want: true},
+		{name: "error interno genérico", err: scenarioError{Status: http.StatusInternalServerError, Code: "InternalServerError", Message: "internal error"}, want: true},
+		{name: "throttling", err: scenarioError{Status: http.StatusTooManyRequests, Code: "TooManyRequests"}},
+		{name: "timeout", err: context.DeadlineExceeded, want: true},
+		{name: "cancelado", err: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ambiguousLaunchError(tt.err); got != tt.want {
+				t.Errorf("ambiguousLaunchError = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// launchScenario tiene dos ADs con dos FDs cada uno
+func launchScenario() *ociScenario {
+	scenario := &ociScenario{Errors: map[string]scenarioError{}}
+	for _, ad := range []string{"Uocm:EU-MADRID-1-AD-1", "Uocm:EU-MADRID-1-AD-2"} {
+		scenario.AvailabilityDomains = append(scenario.AvailabilityDomains, identity.AvailabilityDomain{Name: common.String(ad)})
+		for _, fd := range []string{"FAULT-DOMAIN-1", "FAULT-DOMAIN-2"} {
+			scenario.FaultDomains = append(scenario.FaultDomains, identity.FaultDomain{Name: common.String(fd), AvailabilityDomain: common.String(ad)})
+		}
+	}
+	return scenario
+}
+
+// timeoutOnceCompute crea la instancia en la primera llamada pero devuelve un timeout,
+// como cuando la respuesta de OCI se pierde por el camino
+type timeoutOnceCompute struct {
+	computeAPI
+	tokens []string
+}
+
+func (c *timeoutOnceCompute) LaunchInstance(ctx context.Context, request core.LaunchInstanceRequest) (core.LaunchInstanceResponse, error) {
+	c.tokens = append(c.tokens, stringValue(request.OpcRetryToken))
+	response, err := c.computeAPI.LaunchInstance(ctx, request)
+	if err == nil && len(c.tokens) == 1 {
+		return core.LaunchInstanceResponse{}, context.DeadlineExceeded
+	}
+	return response, err
+}
+
+// launchBackend sustituye el cliente de compute de un backend
+type launchBackend struct {
+	ociBackend
+	compute computeAPI
+}
+
+func (b *launchBackend) Compute() (computeAPI, error) { return b.compute, nil }
+
+func TestInstanceLauncherRun(t *testing.T) {
+	outOfCapacity := scenarioError{Status: http.StatusInternalServerError, Code: "InternalError", Message: "Out of host capacity."}
+
+	t.Run("reintenta hasta encontrar capacidad", func(t *testing.T) {
+		scenario := launchScenario()
+		// Solo AD-2/FAULT-DOMAIN-2 tiene capacidad
+		for _, placement := range []string{"AD-1/FAULT-DOMAIN-1", "AD-1/FAULT-DOMAIN-2", "AD-2/FAULT-DOMAIN-1"} {
+			scenario.Errors["LaunchInstance:Uocm:EU-MADRID-1-"+placement] = outOfCapacity
+		}
+		backend := newScenarioBackend(scenario)
+
+		var notified []Notification
+		launcher := newInstanceLauncher(validTemplate(), launchConfig{Interval: time.Minute, MaxInterval: time.Minute}, backend, "ocid1.tenancy.oc1..test")
+		launcher.usage = func(context.Context) (*AllUsage, error) { return armUsage(0, 0, 0, 0), nil }
+		launcher.notify = func(n Notification) { notified = append(notified, n) }
+		launcher.sleep = func(context.Context, time.Duration) error { return nil }
+
+		instance, err := launcher.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if got := stringValue(instance.FaultDomain); got != "FAULT-DOMAIN-2" || stringValue(instance.AvailabilityDomain) != "Uocm:EU-MADRID-1-AD-2" {
+			t.Errorf("launched in %s/%s", stringValue(instance.AvailabilityDomain), got)
+		}
+		if got := backend.callCount("LaunchInstance"); got != 1 {
+			t.Errorf("successful LaunchInstance calls = %d, want 1", got)
+		}
+		if len(notified) != 1 || notified[0].Event != "instance.launched" || !strings.Contains(notified[0].Text(), "Attempts: 4") {
+			t.Errorf("unexpected notifications %+v", notified)
+		}
+	})
+
+	t.Run("espera entre rondas y respeta max-attempts", func(t *testing.T) {
+		scenario := launchScenario()
+		scenario.Errors["LaunchInstance"] = outOfCapacity
+		backend := newScenarioBackend(scenario)
+
+		var sleeps int
+		launcher := newInstanceLauncher(validTemplate(), launchConfig{Interval: time.Minute, MaxInterval: time.Minute, MaxAttempts: 6}, backend, "ocid1.tenancy.oc1..test")
+		launcher.usage = func(context.Context) (*AllUsage, error) { return armUsage(0, 0, 0, 0), nil }
+		launcher.sleep = func(context.Context, time.Duration) error { sleeps++; return nil }
+
+		_, err := launcher.Run(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "no capacity after 6 attempts") {
+			t.Fatalf("error = %v", err)
+		}
+		if sleeps != 1 {
+			t.Errorf("sleeps = %d, want 1 (one full round of 4 placements)", sleeps)
+		}
+	})
+
+	t.Run("repite con el mismo token tras un timeout", func(t *testing.T) {
+		// El primer LaunchInstance crea la instancia pero la respuesta no llega
+		backend := newScenarioBackend(launchScenario())
+		compute := &timeoutOnceCompute{computeAPI: backend}
+		launcher := newInstanceLauncher(validTemplate(), launchConfig{Interval: time.Minute, MaxInterval: time.Minute}, &launchBackend{backend, compute}, "ocid1.tenancy.oc1..test")
+		checks := 0
+		launcher.usage = func(context.Context) (*AllUsage, error) { checks++; return armUsage(0, 0, 0, 0), nil }
+		launcher.sleep = func(context.Context, time.Duration) error { return nil }
+
+		instance, err := launcher.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if len(backend.scenario.Instances) != 1 {
+			t.Errorf("instances created = %d, want 1", len(backend.scenario.Instances))
+		}
+		if len(compute.tokens) != 2 || compute.tokens[0] == "" || compute.tokens[0] != compute.tokens[1] {
+			t.Errorf("retry tokens = %v, want the same token twice", compute.tokens)
+		}
+		if stringValue(instance.Id) != stringValue(backend.scenario.Instances[0].Id) {
+			t.Errorf("launched %s, want the instance created by the first call", stringValue(instance.Id))
+		}
+		if checks != 1 {
+			t.Errorf("headroom checks = %d, want 1", checks)
+		}
+	})
+
+	t.Run("último intento ambiguo devuelve el token", func(t *testing.T) {
+		// El único intento crea la instancia pero la respuesta no llega
+		backend := newScenarioBackend(launchScenario())
+		compute := &timeoutOnceCompute{computeAPI: backend}
+		launcher := newInstanceLauncher(validTemplate(), launchConfig{Interval: time.Minute, MaxInterval: time.Minute, MaxAttempts: 1}, &launchBackend{backend, compute}, "ocid1.tenancy.oc1..test")
+		launcher.usage = func(context.Context) (*AllUsage, error) { return armUsage(0, 0, 0, 0), nil }
+		launcher.sleep = func(context.Context, time.Duration) error { return nil }
+
+		_, err := launcher.Run(context.Background())
+		if !errors.Is(err, errLaunchUnknown) {
+			t.Fatalf("error = %v; want %v", err, errLaunchUnknown)
+		}
+		if len(compute.tokens) != 1 || !strings.Contains(err.Error(), compute.tokens[0]) {
+			t.Errorf("error = %v; want it to include the retry token %v", err, compute.tokens)
+		}
+	})
+
+	t.Run("rechaza si no cabe en la Free Tier", func(t *testing.T) {
+		backend := newScenarioBackend(launchScenario())
+		launcher := newInstanceLauncher(validTemplate(), currentLaunchConfig(), backend, "ocid1.tenancy.oc1..test")
+		launcher.usage = func(context.Context) (*AllUsage, error) { return armUsage(4, 24, 1, 50), nil }
+
+		_, err := launcher.Run(context.Background())
+		if !errors.Is(err, errLaunchHeadroom) {
+			t.Fatalf("error = %v, want headroom refusal", err)
+		}
+		if got := backend.callCount("LaunchInstance"); got != 0 {
+			t.Errorf("LaunchInstance called %d times", got)
+		}
+	})
+
+	t.Run("error no recuperable", func(t *testing.T) {
+		scenario := launchScenario()
+		scenario.AvailabilityDomains = scenario.AvailabilityDomains[:1]
+		scenario.Errors["LaunchInstance"] = scenarioError{Status: http.StatusNotFound, Code: "NotAuthorizedOrNotFound", Message: "image not found"}
+		backend := newScenarioBackend(scenario)
+		launcher := newInstanceLauncher(validTemplate(), currentLaunchConfig(), backend, "ocid1.tenancy.oc1..test")
+		launcher.usage = func(context.Context) (*AllUsage, error) { return armUsage(0, 0, 0, 0), nil }
+		launcher.template.AvailabilityDomains = []string{"ad-1"}
+
+		_, err := launcher.Run(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "image not found") {
+			t.Fatalf("error = %v", err)
+		}
+	})
+}
+
+// TestGetLaunchUsage verifica que la comprobación de margen solo consulta compute y block storage
+func TestGetLaunchUsage(t *testing.T) {
+	clearOCIEnv(t)
+	setTestOCIEnv(t)
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyPath, []byte("unused"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OCI_PRIVATE_KEY_PATH", keyPath)
+	t.Setenv("OCI_COMPARTMENT_ID", testCompartment)
+	backend := newScenarioBackend(loadFixture(t, "over_limits.json"))
+	useFakeBackend(t, backend)
+
+	usage, err := getLaunchUsage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := collectCompartment(context.Background(), newScenarioBackend(loadFixture(t, "over_limits.json")), testCompartment, testPagination, currentTimeouts())
+	if usage.Compute.ARM != full.Compute.ARM || usage.BlockStorage.Total != full.BlockStorage.Total {
+		t.Errorf("usage = %+v / %+v; want %+v / %+v", usage.Compute.ARM, usage.BlockStorage.Total, full.Compute.ARM, full.BlockStorage.Total)
+	}
+	for _, operation := range []string{"GetNamespace", "ListLoadBalancers", "ListAutonomousDatabases", "ListPublicIps"} {
+		if calls := backend.callCount(operation); calls != 0 {
+			t.Errorf("%s calls = %d; want 0", operation, calls)
+		}
+	}
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	for operation := range backend.calls {
+		if strings.HasPrefix(operation, "SummarizeMetricsData") {
+			t.Errorf("%s called; want no Monitoring queries", operation)
+		}
+	}
+}
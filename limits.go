@@ -0,0 +1,196 @@
+// Package main - Este archivo carga los límites de la Free Tier desde un fichero YAML/JSON
+// con recarga en caliente (SIGHUP o cambio del fichero)
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultLimitsYAML son los límites Always Free actuales, embebidos en el binario
+//
+//go:embed limits.default.yaml
+var defaultLimitsYAML []byte
+
+// LimitsSource indica de dónde salen los límites activos
+type LimitsSource struct {
+	Source   string    // "embedded" o la ruta del fichero
+	LoadedAt time.Time // momento de la última carga
+}
+
+// limitsMu protege Limits y limitsSource frente a las recargas en caliente
+var (
+	limitsMu     sync.RWMutex
+	limitsSource = LimitsSource{Source: "embedded"}
+)
+
+// embeddedLimits son los límites por defecto; los ficheros solo necesitan indicar lo que cambia
+var embeddedLimits FreeTierLimits
+
+// init carga los límites embebidos para que siempre haya valores válidos
+func init() {
+	limits, err := parseLimitsOnto(FreeTierLimits{}, defaultLimitsYAML)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded limits: %v", err))
+	}
+	embeddedLimits = limits
+	Limits = limits
+	limitsSource.LoadedAt = time.Now().UTC()
+}
+
+// currentLimits devuelve una copia de los límites activos
+func currentLimits() FreeTierLimits {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return Limits
+}
+
+// currentLimitsWithSource devuelve los límites activos junto con su origen
+func currentLimitsWithSource() (FreeTierLimits, LimitsSource) {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return Limits, limitsSource
+}
+
+// setLimits reemplaza los límites activos
+func setLimits(limits FreeTierLimits, source string) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	Limits = limits
+	limitsSource = LimitsSource{Source: source, LoadedAt: time.Now().UTC()}
+}
+
+// parseLimits interpreta un documento YAML (o JSON, que es YAML válido) y lo valida
+// Los campos que falten se toman de los límites embebidos
+func parseLimits(data []byte) (FreeTierLimits, error) {
+	return parseLimitsOnto(embeddedLimits, data)
+}
+
+// parseLimitsOnto aplica el documento sobre base y valida el resultado
+func parseLimitsOnto(base FreeTierLimits, data []byte) (FreeTierLimits, error) {
+	limits := base
+
+	// YAML → mapa genérico → JSON: así reutilizamos los tags json del struct
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return limits, fmt.Errorf("invalid YAML/JSON: %w", err)
+	}
+	asJSON, err := json.Marshal(doc)
+	if err != nil {
+		return limits, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(asJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&limits); err != nil {
+		return limits, fmt.Errorf("invalid limits: %w", err)
+	}
+
+	if err := validateLimits(limits); err != nil {
+		return limits, err
+	}
+	return limits, nil
+}
+
+// validateLimits comprueba que todos los límites son positivos
+// Un límite a 0 provocaría divisiones por cero al calcular porcentajes
+func validateLimits(limits FreeTierLimits) error {
+	return validateLimitFields(reflect.ValueOf(limits), "")
+}
+
+// validateLimitFields recorre el struct siguiendo los tags json
+func validateLimitFields(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonFieldName(t.Field(i))
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			if err := validateLimitFields(field, path); err != nil {
+				return err
+			}
+		case reflect.Int:
+			if field.Int() <= 0 {
+				return fmt.Errorf("limit %s must be positive, got %d", path, field.Int())
+			}
+		case reflect.Float64:
+			if field.Float() <= 0 {
+				return fmt.Errorf("limit %s must be positive, got %g", path, field.Float())
+			}
+		}
+	}
+	return nil
+}
+
+// loadLimitsFile lee y valida un fichero de límites y lo activa
+func loadLimitsFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading limits file: %w", err)
+	}
+	limits, err := parseLimits(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	setLimits(limits, path)
+	return nil
+}
+
+// watchLimitsFile recarga el fichero al recibir SIGHUP o cuando cambia su fecha/tamaño
+// Si la nueva versión no es válida se mantiene la anterior
+func watchLimitsFile(path string, interval time.Duration) {
+	reload := func(reason string) {
+		if err := loadLimitsFile(path); err != nil {
+			logger.Error().Err(err).Str("reason", reason).Msg("Limits reload failed, keeping previous limits")
+			return
+		}
+		logger.Info().Str("path", path).Str("reason", reason).Msg("Free Tier limits reloaded")
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	lastMod, lastSize := limitsFileStamp(path)
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-hup:
+				reload("SIGHUP")
+				lastMod, lastSize = limitsFileStamp(path)
+			case <-ticker.C:
+				mod, size := limitsFileStamp(path)
+				if !mod.Equal(lastMod) || size != lastSize {
+					lastMod, lastSize = mod, size
+					reload("file changed")
+				}
+			}
+		}
+	}()
+}
+
+// limitsFileStamp devuelve la fecha de modificación y el tamaño del fichero
+func limitsFileStamp(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
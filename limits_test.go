@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+)
+
+// TestEmbeddedLimits verifica que los límites embebidos mantienen los valores históricos
+func TestEmbeddedLimits(t *testing.T) {
+	limits := currentLimits()
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{name: "ARM OCPUs", got: limits.Compute.ARM.OCPUs, want: 4},
+		{name: "ARM memoria", got: limits.Compute.ARM.MemoryGB, want: 24},
+		{name: "AMD instancias", got: float64(limits.Compute.AMD.MaxInstances), want: 2},
+		{name: "block storage", got: float64(limits.BlockStorage.TotalGB), want: 200},
+		{name: "object storage", got: float64(limits.ObjectStorage.TotalGB), want: 10},
+		{name: "IPs públicas", got: float64(limits.PublicIPs.Reserved), want: 2},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v; want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// TestParseLimits verifica la lectura de YAML/JSON parciales y la validación
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		name         string
+		doc          string
+		wantBlockGB  int
+		wantARMOCPUs float64
+		wantErr      bool
+	}{
+		{
+			name:         "YAML parcial hereda el resto",
+			doc:          "blockStorage:\n  totalGB: 400\n",
+			wantBlockGB:  400,
+			wantARMOCPUs: 4,
+		},
+		{
+			name:         "JSON también se acepta",
+			doc:          `{"compute": {"arm": {"ocpus": 8}}}`,
+			wantBlockGB:  200,
+			wantARMOCPUs: 8,
+		},
+		{name: "campo desconocido", doc: "blockStorage:\n  totalTB: 1\n", wantErr: true},
+		{name: "límite a cero", doc: "loadBalancer:\n  instances: 0\n", wantErr: true},
+		{name: "documento inválido", doc: "compute: [", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits, err := parseLimits([]byte(tt.doc))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLimits() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if limits.BlockStorage.TotalGB != tt.wantBlockGB {
+				t.Errorf("BlockStorage.TotalGB = %d; want %d", limits.BlockStorage.TotalGB, tt.wantBlockGB)
+			}
+			if limits.Compute.ARM.OCPUs != tt.wantARMOCPUs {
+				t.Errorf("Compute.ARM.OCPUs = %v; want %v", limits.Compute.ARM.OCPUs, tt.wantARMOCPUs)
+			}
+		})
+	}
+}
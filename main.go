@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,40 +43,27 @@ type FreeTierLimits struct {
 	} `json:"bandwidth"`
 	Database struct {
 		AutonomousDBs  int `json:"autonomousDBs"`
-		TotalStorageGB int `json:"totalStorageGB"`
+		TotalStorageGB int `json:"totalStorageGB"` // por cada base de datos Always Free
 	} `json:"database"`
 	LoadBalancer struct {
-		Instances    int `json:"instances"`
+		Instances     int `json:"instances"`
 		BandwidthMbps int `json:"bandwidthMbps"`
 	} `json:"loadBalancer"`
+	PublicIPs struct {
+		Reserved int `json:"reserved"`
+	} `json:"publicIPs"`
 }
 
 // Limits contiene los valores de la Free Tier de Oracle Cloud
-// Esta es una variable global (a nivel de paquete)
+// Esta es una variable global (a nivel de paquete); se carga desde limits.go
+// y puede recargarse en caliente, así que se lee siempre con currentLimits()
 var Limits = FreeTierLimits{}
 
 // logger es el logger estructurado global
 var logger zerolog.Logger
 
-// init() se ejecuta automáticamente antes de main()
-// Es útil para inicializar variables globales
-func init() {
-	// Inicializar los límites de Free Tier
-	Limits.Compute.ARM.OCPUs = 4
-	Limits.Compute.ARM.MemoryGB = 24
-	Limits.Compute.ARM.MaxInstances = 4
-	Limits.Compute.AMD.OCPUs = 0.25
-	Limits.Compute.AMD.MemoryGB = 1
-	Limits.Compute.AMD.MaxInstances = 2
-	Limits.BlockStorage.TotalGB = 200
-	Limits.ObjectStorage.TotalGB = 10
-	Limits.ObjectStorage.RequestsPerMonth = 50000
-	Limits.Bandwidth.EgressTBPerMonth = 10
-	Limits.Database.AutonomousDBs = 2
-	Limits.Database.TotalStorageGB = 20
-	Limits.LoadBalancer.Instances = 1
-	Limits.LoadBalancer.BandwidthMbps = 10
-}
+// poller mantiene el snapshot de uso en caché que sirven /usage y /status
+var poller *UsagePoller
 
 // UsageMetric representa una métrica de uso individual
 type UsageMetric struct {
@@ -94,8 +82,28 @@ type ComputeUsage struct {
 	AMD struct {
 		Instances UsageMetric `json:"instances"`
 	} `json:"amd"`
-	TotalInstances int    `json:"totalInstances"`
-	Error          string `json:"error,omitempty"`
+	TotalInstances int            `json:"totalInstances"`
+	Instances      []InstanceInfo `json:"instances"`
+	Truncated      bool           `json:"truncated,omitempty"`
+	Error          string         `json:"error,omitempty"`
+}
+
+// InstanceInfo contiene info de una instancia en ejecución
+type InstanceInfo struct {
+	ID                 string  `json:"id"`
+	Name               string  `json:"name"`
+	Shape              string  `json:"shape"`
+	Arch               string  `json:"arch"` // "arm", "amd" u "other"
+	OCPUs              float64 `json:"ocpus"`
+	MemoryGB           float64 `json:"memoryGB"`
+	AvailabilityDomain string  `json:"availabilityDomain"`
+	CompartmentID      string  `json:"compartmentId"`
+	Region             string  `json:"region,omitempty"`
+	// NetworkBandwidthGbps es el ancho de banda de la shape, para calcular el % de red
+	NetworkBandwidthGbps float64 `json:"networkBandwidthGbps,omitempty"`
+	// Billing es ALWAYS_FREE, FREE_WITH_CONDITIONS o BILLABLE (ver billing.go)
+	Billing       string `json:"billing,omitempty"`
+	BillingReason string `json:"billingReason,omitempty"`
 }
 
 // StorageUsage contiene el uso de almacenamiento
@@ -108,35 +116,121 @@ type StorageUsage struct {
 		Count  int `json:"count"`
 		SizeGB int `json:"sizeGB"`
 	} `json:"blockVolumes"`
-	Total UsageMetric `json:"total"`
-	Error string      `json:"error,omitempty"`
+	Total     UsageMetric  `json:"total"`
+	Volumes   []VolumeInfo `json:"volumes"`
+	Truncated bool         `json:"truncated,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// VolumeInfo contiene info de un boot volume o block volume
+type VolumeInfo struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	Type               string `json:"type"` // "boot" o "block"
+	SizeGB             int    `json:"sizeGB"`
+	AvailabilityDomain string `json:"availabilityDomain"`
+	CompartmentID      string `json:"compartmentId"`
+	Region             string `json:"region,omitempty"`
+	VpusPerGB          *int64 `json:"vpusPerGB,omitempty"` // rendimiento efectivo (incluye auto-tune)
+	Billing            string `json:"billing,omitempty"`
+	BillingReason      string `json:"billingReason,omitempty"`
 }
 
 // ObjectStorageUsage contiene el uso de object storage
+// Requests son las peticiones del mes en curso según OCI Monitoring (AllRequests)
 type ObjectStorageUsage struct {
-	Buckets []BucketInfo `json:"buckets"`
-	Total   UsageMetric  `json:"total"`
-	Error   string       `json:"error,omitempty"`
+	Buckets           []BucketInfo `json:"buckets"`
+	Total             UsageMetric  `json:"total"`
+	Requests          UsageMetric  `json:"requests"`
+	ProjectedRequests UsageMetric  `json:"projectedRequests"` // estimación a fin de mes al ritmo actual
+	Truncated         bool         `json:"truncated,omitempty"`
+	Error             string       `json:"error,omitempty"`
 }
 
 // BucketInfo contiene info de un bucket
 type BucketInfo struct {
-	Name   string  `json:"name"`
-	SizeGB float64 `json:"sizeGB"`
+	Name          string  `json:"name"`
+	SizeGB        float64 `json:"sizeGB"`
+	Requests      float64 `json:"requests"` // peticiones del mes en curso
+	CompartmentID string  `json:"compartmentId"`
+	Region        string  `json:"region,omitempty"`
 }
 
 // LoadBalancerUsage contiene el uso de load balancers
 type LoadBalancerUsage struct {
 	Count         UsageMetric        `json:"count"`
 	LoadBalancers []LoadBalancerInfo `json:"loadBalancers"`
+	Truncated     bool               `json:"truncated,omitempty"`
 	Error         string             `json:"error,omitempty"`
 }
 
 // LoadBalancerInfo contiene info de un load balancer
 type LoadBalancerInfo struct {
-	Name  string `json:"name"`
-	Shape string `json:"shape"`
-	State string `json:"state"`
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Shape         string `json:"shape"`
+	State         string `json:"state"`
+	CompartmentID string `json:"compartmentId"`
+	Region        string `json:"region,omitempty"`
+	// MaxBandwidthMbps es el máximo del LB flexible (0 si la shape es fija o no se conoce)
+	MaxBandwidthMbps int    `json:"maxBandwidthMbps,omitempty"`
+	Billing          string `json:"billing,omitempty"`
+	BillingReason    string `json:"billingReason,omitempty"`
+}
+
+// DatabaseUsage contiene el uso de Autonomous Database
+// Las bases de datos de pago no cuentan contra la asignación gratuita: se listan en Paid
+type DatabaseUsage struct {
+	AutonomousDBs UsageMetric    `json:"autonomousDBs"` // bases de datos Always Free
+	StorageGB     UsageMetric    `json:"storageGB"`     // almacenamiento de las Always Free
+	Paid          int            `json:"paid"`
+	Databases     []DatabaseInfo `json:"databases"`
+	Truncated     bool           `json:"truncated,omitempty"`
+	Error         string         `json:"error,omitempty"`
+}
+
+// DatabaseInfo contiene info de una Autonomous Database
+type DatabaseInfo struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	IsFreeTier    bool   `json:"isFreeTier"`
+	StorageGB     int    `json:"storageGB"`
+	State         string `json:"state"`
+	CompartmentID string `json:"compartmentId"`
+	Region        string `json:"region,omitempty"`
+}
+
+// BandwidthUsage contiene el tráfico de salida del mes en curso según OCI Monitoring
+// Las cifras de bytes se suman entre compartimentos y regiones; la proyección también,
+// porque todas se calculan sobre el mismo periodo
+type BandwidthUsage struct {
+	EgressTB          UsageMetric `json:"egressTB"`    // salida desde el día 1 del mes (cota superior)
+	ProjectedTB       UsageMetric `json:"projectedTB"` // salida estimada a fin de mes al ritmo actual
+	VnicBytes         float64     `json:"vnicBytes"`   // VnicToNetworkBytes de todas las VNICs
+	LoadBalancerBytes float64     `json:"loadBalancerBytes"`
+	Error             string      `json:"error,omitempty"`
+}
+
+// ReclamationUsage contiene el riesgo de que Oracle reclame instancias Always Free inactivas
+type ReclamationUsage struct {
+	Instances []ReclamationRisk `json:"instances"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// ReclamationRisk es el riesgo de reclamación de una instancia
+// Los percentiles son p95 de los últimos 7 días, en % (red: % del ancho de banda de la shape)
+type ReclamationRisk struct {
+	InstanceID    string   `json:"instanceId"`
+	Name          string   `json:"name"`
+	Shape         string   `json:"shape"`
+	Risk          string   `json:"risk"`                 // OK, AT_RISK, RECLAIMABLE o UNKNOWN (sin métricas)
+	CPUP95        *float64 `json:"cpuP95,omitempty"`     // nil si no hay datos
+	MemoryP95     *float64 `json:"memoryP95,omitempty"`  // solo cuenta en shapes A1
+	NetworkP95    *float64 `json:"networkP95,omitempty"` // nil si no hay datos
+	IdleDays      int      `json:"idleDays"`             // días inactivos seguidos hasta hoy
+	DaysRemaining int      `json:"daysRemaining"`        // días hasta cumplir 7 inactivos
+	CompartmentID string   `json:"compartmentId"`
+	Region        string   `json:"region,omitempty"`
 }
 
 // AllUsage contiene todo el uso
@@ -146,40 +240,67 @@ type AllUsage struct {
 	PublicIPs     UsageMetric        `json:"publicIPs"`
 	ObjectStorage ObjectStorageUsage `json:"objectStorage"`
 	LoadBalancer  LoadBalancerUsage  `json:"loadBalancer"`
+	Database      DatabaseUsage      `json:"database"`
+	Bandwidth     BandwidthUsage     `json:"bandwidth"`
+	Reclamation   ReclamationUsage   `json:"reclamation"`
+	// Truncated lista los colectores que alcanzaron OCI_MAX_PAGES (el uso real puede ser mayor)
+	Truncated []string `json:"truncated,omitempty"`
+	// TimedOut lista los colectores que agotaron su timeout (sus datos están incompletos)
+	TimedOut []string `json:"timedOut,omitempty"`
+	// Errors describe los colectores que fallaron: sus métricas no son fiables
+	Errors []CollectorError `json:"errors,omitempty"`
+	// Compartments desglosa el uso por compartimento (solo con OCI_COMPARTMENT_SCAN=recursive)
+	Compartments []CompartmentUsage `json:"compartments,omitempty"`
+	// Regions desglosa el uso por región (solo con OCI_REGION_SCAN=all)
+	Regions []RegionUsage `json:"regions,omitempty"`
 }
 
 // UsageResponse es la respuesta del endpoint /usage
 type UsageResponse struct {
-	Status             string         `json:"status"`
-	MaxUsagePercentage int            `json:"maxUsagePercentage"`
-	Warnings           []string       `json:"warnings"`
-	Timestamp          string         `json:"timestamp"`
-	Configured         bool           `json:"configured"`
-	Usage              *AllUsage      `json:"usage,omitempty"`
-	FreeTierLimits     FreeTierLimits `json:"freeTierLimits"`
-	Error              string         `json:"error,omitempty"`
-	Message            string         `json:"message,omitempty"`
+	Status             string           `json:"status"`
+	MaxUsagePercentage int              `json:"maxUsagePercentage"`
+	Warnings           []string         `json:"warnings"`
+	Findings           []Finding        `json:"findings,omitempty"`
+	Timestamp          string           `json:"timestamp"`
+	Configured         bool             `json:"configured"`
+	CollectedAt        string           `json:"collectedAt,omitempty"`
+	AgeSeconds         int              `json:"ageSeconds"`
+	Usage              *AllUsage        `json:"usage,omitempty"`
+	FreeTierLimits     FreeTierLimits   `json:"freeTierLimits"`
+	Keepalive          *KeepaliveStatus `json:"keepalive,omitempty"` // solo con KEEPALIVE_ENABLED
+	Error              string           `json:"error,omitempty"`
+	Message            string           `json:"message,omitempty"`
 }
 
 // HealthResponse es la respuesta del endpoint /health
 type HealthResponse struct {
-	Status    string `json:"status"`
-	Timestamp string `json:"timestamp"`
+	Status     string `json:"status"`
+	AuthMode   string `json:"authMode"`
+	Configured bool   `json:"configured"`
+	Timestamp  string `json:"timestamp"`
 }
 
 // StatusResponse es la respuesta del endpoint /status
 type StatusResponse struct {
-	Status             string   `json:"status"`
-	MaxUsagePercentage int      `json:"maxUsagePercentage,omitempty"`
-	Warnings           []string `json:"warnings,omitempty"`
-	Timestamp          string   `json:"timestamp"`
-	Message            string   `json:"message,omitempty"`
+	Status             string           `json:"status"`
+	MaxUsagePercentage int              `json:"maxUsagePercentage,omitempty"`
+	Warnings           []string         `json:"warnings,omitempty"`
+	Findings           []Finding        `json:"findings,omitempty"`
+	Errors             []CollectorError `json:"errors,omitempty"`
+	Timestamp          string           `json:"timestamp"`
+	CollectedAt        string           `json:"collectedAt,omitempty"`
+	AgeSeconds         int              `json:"ageSeconds"`
+	Message            string           `json:"message,omitempty"`
 }
 
 // LimitsResponse es la respuesta del endpoint /limits
 type LimitsResponse struct {
-	FreeTierLimits FreeTierLimits `json:"freeTierLimits"`
-	Timestamp      string         `json:"timestamp"`
+	FreeTierLimits FreeTierLimits       `json:"freeTierLimits"`
+	Source         string               `json:"source"`
+	LoadedAt       string               `json:"loadedAt"`
+	Thresholds     ThresholdConfig      `json:"thresholds"`
+	ServiceLimits  *ServiceLimitsReport `json:"serviceLimits,omitempty"`
+	Timestamp      string               `json:"timestamp"`
 }
 
 // getEnv obtiene una variable de entorno con un valor por defecto
@@ -191,20 +312,75 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvDuration obtiene una duración (ej. "5m", "30s") de una variable de entorno
+// Si el valor no es válido, avisa y usa el valor por defecto
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		logger.Warn().Str("key", key).Str("value", value).Msg("Invalid duration, using default")
+		return defaultValue
+	}
+	return d
+}
+
+// getEnvInt obtiene un entero positivo de una variable de entorno
+// Si el valor no es válido, avisa y usa el valor por defecto
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		logger.Warn().Str("key", key).Str("value", value).Msg("Invalid integer, using default")
+		return defaultValue
+	}
+	return n
+}
+
+// getEnvFloat obtiene un número decimal positivo de una variable de entorno
+// Si el valor no es válido, avisa y usa el valor por defecto
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f <= 0 {
+		logger.Warn().Str("key", key).Str("value", value).Msg("Invalid number, using default")
+		return defaultValue
+	}
+	return f
+}
+
+// wantsRefresh indica si el cliente pidió saltarse la caché con ?refresh=true
+func wantsRefresh(r *http.Request) bool {
+	refresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+	return refresh
+}
+
 // isConfigured verifica si las credenciales de OCI están configuradas
+// Lo necesario depende de OCI_AUTH_MODE
 func isConfigured() bool {
-	required := []string{
-		"OCI_TENANCY_ID",
-		"OCI_USER_ID",
-		"OCI_FINGERPRINT",
-		"OCI_PRIVATE_KEY_PATH",
-		"OCI_REGION",
-	}
-	for _, key := range required {
+	mode := currentAuthMode()
+	if !validAuthMode(mode) {
+		return false
+	}
+	for _, key := range authRequiredEnv(mode) {
 		if os.Getenv(key) == "" {
 			return false
 		}
 	}
+	if mode == AuthModeConfigFile || mode == AuthModeSecurityToken {
+		path, _ := ociConfigFile()
+		if _, err := os.Stat(path); err != nil {
+			return false
+		}
+	}
 	return true
 }
 
@@ -229,22 +405,36 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	writeJSON(w, http.StatusOK, HealthResponse{
-		Status:    "ok",
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Status:     "ok",
+		AuthMode:   currentAuthMode(),
+		Configured: isConfigured(),
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
 // limitsHandler maneja GET /limits
+// Devuelve la asignación Always Free y, si OCI está configurado, los límites reales de la tenancy
 func limitsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, LimitsResponse{
-		FreeTierLimits: Limits,
+	limits, source := currentLimitsWithSource()
+	response := LimitsResponse{
+		FreeTierLimits: limits,
+		Source:         source.Source,
+		LoadedAt:       source.LoadedAt.Format(time.RFC3339),
+		Thresholds:     thresholds,
 		Timestamp:      time.Now().UTC().Format(time.RFC3339),
-	})
+	}
+
+	if isConfigured() {
+		ttl := getEnvDuration("SERVICE_LIMITS_TTL", time.Hour)
+		response.ServiceLimits = getServiceLimitsCached(r.Context(), ttl, wantsRefresh(r))
+	}
+
+	writeJSON(w, http.StatusOK, response)
 }
 
 // usageHandler maneja GET /usage
@@ -261,83 +451,41 @@ func usageHandler(w http.ResponseWriter, r *http.Request) {
 			Timestamp:      time.Now().UTC().Format(time.RFC3339),
 			Error:          "OCI not configured",
 			Message:        "Please configure your OCI credentials in the .env file",
-			FreeTierLimits: Limits,
+			FreeTierLimits: currentLimits(),
+			Keepalive:      keepaliveStatus(),
 		})
 		return
 	}
 
-	// Obtener uso real de OCI
-	usage, err := getOCIUsage()
+	// Obtener el uso desde la caché (o recolectar si se pide ?refresh=true)
+	usage, collectedAt, err := poller.Snapshot(r.Context(), wantsRefresh(r))
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, UsageResponse{
 			Status:         "ERROR",
 			Configured:     true,
 			Timestamp:      time.Now().UTC().Format(time.RFC3339),
 			Error:          err.Error(),
-			FreeTierLimits: Limits,
+			FreeTierLimits: currentLimits(),
+			Keepalive:      keepaliveStatus(),
 		})
 		return
 	}
 
 	// Calcular estado general
-	percentages := []int{}
-	warnings := []string{}
-
-	if usage.Compute.ARM.OCPUs.Percentage > 0 {
-		percentages = append(percentages, usage.Compute.ARM.OCPUs.Percentage)
-		if usage.Compute.ARM.OCPUs.Percentage >= 80 {
-			warnings = append(warnings, fmt.Sprintf("ARM OCPUs at %d%%", usage.Compute.ARM.OCPUs.Percentage))
-		}
-	}
-	if usage.Compute.ARM.MemoryGB.Percentage > 0 {
-		percentages = append(percentages, usage.Compute.ARM.MemoryGB.Percentage)
-		if usage.Compute.ARM.MemoryGB.Percentage >= 80 {
-			warnings = append(warnings, fmt.Sprintf("ARM Memory at %d%%", usage.Compute.ARM.MemoryGB.Percentage))
-		}
-	}
-	if usage.BlockStorage.Total.Percentage > 0 {
-		percentages = append(percentages, usage.BlockStorage.Total.Percentage)
-		if usage.BlockStorage.Total.Percentage >= 80 {
-			warnings = append(warnings, fmt.Sprintf("Block Storage at %d%%", usage.BlockStorage.Total.Percentage))
-		}
-	}
-	if usage.PublicIPs.Percentage > 0 {
-		percentages = append(percentages, usage.PublicIPs.Percentage)
-		if usage.PublicIPs.Percentage >= 80 {
-			warnings = append(warnings, fmt.Sprintf("Public IPs at %d%%", usage.PublicIPs.Percentage))
-		}
-	}
-	if usage.ObjectStorage.Total.Percentage > 0 {
-		percentages = append(percentages, usage.ObjectStorage.Total.Percentage)
-		if usage.ObjectStorage.Total.Percentage >= 80 {
-			warnings = append(warnings, fmt.Sprintf("Object Storage at %d%%", usage.ObjectStorage.Total.Percentage))
-		}
-	}
-
-	maxPercentage := 0
-	for _, p := range percentages {
-		if p > maxPercentage {
-			maxPercentage = p
-		}
-	}
-
-	status := "OK"
-	if maxPercentage >= 90 {
-		status = "CRITICAL"
-	} else if maxPercentage >= 80 {
-		status = "WARNING"
-	} else if maxPercentage >= 60 {
-		status = "ATTENTION"
-	}
+	eval := evaluateUsage(usage)
 
 	writeJSON(w, http.StatusOK, UsageResponse{
-		Status:             status,
-		MaxUsagePercentage: maxPercentage,
-		Warnings:           warnings,
+		Status:             eval.Status,
+		MaxUsagePercentage: eval.MaxPercentage,
+		Warnings:           eval.Warnings(),
+		Findings:           eval.Findings,
 		Timestamp:          time.Now().UTC().Format(time.RFC3339),
 		Configured:         true,
+		CollectedAt:        collectedAt.Format(time.RFC3339),
+		AgeSeconds:         snapshotAge(collectedAt),
 		Usage:              usage,
-		FreeTierLimits:     Limits,
+		FreeTierLimits:     currentLimits(),
+		Keepalive:          keepaliveStatus(),
 	})
 }
 
@@ -357,7 +505,7 @@ func statusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	usage, err := getOCIUsage()
+	usage, collectedAt, err := poller.Snapshot(r.Context(), wantsRefresh(r))
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, StatusResponse{
 			Status:    "ERROR",
@@ -367,40 +515,25 @@ func statusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Calcular estado
-	percentages := []int{
-		usage.Compute.ARM.OCPUs.Percentage,
-		usage.Compute.ARM.MemoryGB.Percentage,
-		usage.BlockStorage.Total.Percentage,
-		usage.ObjectStorage.Total.Percentage,
-		usage.PublicIPs.Percentage,
-	}
-
-	maxPercentage := 0
-	warnings := []string{}
-	for _, p := range percentages {
-		if p > maxPercentage {
-			maxPercentage = p
-		}
-		if p >= 80 {
-			warnings = append(warnings, fmt.Sprintf("Resource at %d%%", p))
-		}
-	}
+	// Calcular estado con el mismo evaluador que /usage
+	eval := evaluateUsage(usage)
 
-	status := "OK"
-	if maxPercentage >= 90 {
-		status = "CRITICAL"
-	} else if maxPercentage >= 80 {
-		status = "WARNING"
-	} else if maxPercentage >= 60 {
-		status = "ATTENTION"
+	// Si algún colector falló los datos no son fiables: 503 para que los
+	// health checks externos no den por bueno un 0% que en realidad es "sin datos"
+	code := http.StatusOK
+	if !eval.Reliable() {
+		code = http.StatusServiceUnavailable
 	}
 
-	writeJSON(w, http.StatusOK, StatusResponse{
-		Status:             status,
-		MaxUsagePercentage: maxPercentage,
-		Warnings:           warnings,
+	writeJSON(w, code, StatusResponse{
+		Status:             eval.Status,
+		MaxUsagePercentage: eval.MaxPercentage,
+		Warnings:           eval.Warnings(),
+		Findings:           eval.Findings,
+		Errors:             eval.Errors,
 		Timestamp:          time.Now().UTC().Format(time.RFC3339),
+		CollectedAt:        collectedAt.Format(time.RFC3339),
+		AgeSeconds:         snapshotAge(collectedAt),
 	})
 }
 
@@ -414,7 +547,7 @@ func authMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		}
 
 		apiKey := os.Getenv("API_KEY")
-		
+
 		// Si no hay API_KEY configurada, permitir acceso (desarrollo)
 		if apiKey == "" {
 			logger.Warn().Msg("API_KEY not set - endpoints are unprotected")
@@ -452,51 +585,69 @@ func authMiddleware(next http.HandlerFunc) http.HandlerFunc {
 }
 
 // validateEnvVars valida que las variables de entorno críticas estén configuradas
+// y que el proveedor de autenticación se puede crear (clave legible, passphrase correcta...)
 func validateEnvVars() error {
-	required := map[string]string{
-		"OCI_TENANCY_ID":       os.Getenv("OCI_TENANCY_ID"),
-		"OCI_USER_ID":          os.Getenv("OCI_USER_ID"),
-		"OCI_FINGERPRINT":      os.Getenv("OCI_FINGERPRINT"),
-		"OCI_PRIVATE_KEY_PATH": os.Getenv("OCI_PRIVATE_KEY_PATH"),
-		"OCI_REGION":           os.Getenv("OCI_REGION"),
+	mode := currentAuthMode()
+	if !validAuthMode(mode) {
+		logger.Error().Str("auth_mode", mode).Strs("valid", authModes).Msg("Unknown OCI_AUTH_MODE")
+		return fmt.Errorf("unknown OCI_AUTH_MODE %q", mode)
 	}
 
 	var missing []string
-	for key, value := range required {
-		if value == "" {
+	for _, key := range authRequiredEnv(mode) {
+		if os.Getenv(key) == "" {
 			missing = append(missing, key)
 		}
 	}
 
 	if len(missing) > 0 {
 		logger.Warn().
+			Str("auth_mode", mode).
 			Strs("missing_vars", missing).
 			Msg("OCI credentials not fully configured - some endpoints will return NOT_CONFIGURED")
 		return fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
 	}
 
 	// Verificar que el archivo de clave privada existe
-	keyPath := os.Getenv("OCI_PRIVATE_KEY_PATH")
-	if _, err := os.Stat(keyPath); os.IsNotExist(err) {
-		logger.Error().
-			Str("path", keyPath).
-			Msg("Private key file not found")
-		return fmt.Errorf("private key file not found: %s", keyPath)
+	if mode == AuthModeAPIKey {
+		keyPath := os.Getenv("OCI_PRIVATE_KEY_PATH")
+		if _, err := os.Stat(keyPath); os.IsNotExist(err) {
+			logger.Error().
+				Str("path", keyPath).
+				Msg("Private key file not found")
+			return fmt.Errorf("private key file not found: %s", keyPath)
+		}
+	}
+
+	// Crear el proveedor comprueba el fichero de configuración o el servicio de metadatos;
+	// leer la clave comprueba además la passphrase
+	provider, err := createConfigProvider()
+	if err == nil && mode != AuthModeInstancePrincipal {
+		_, err = provider.PrivateRSAKey()
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("auth_mode", mode).Msg("Cannot create OCI authentication provider")
+		return err
 	}
 
-	logger.Info().Msg("OCI credentials validated successfully")
+	logger.Info().Str("auth_mode", mode).Msg("OCI credentials validated successfully")
 	return nil
 }
 
 // main es el punto de entrada del programa
 func main() {
 	// Configurar logger estructurado
+	// Con un subcomando del CLI (ej. "watcher check") los logs van a stderr para no mezclarse con la salida
+	logOutput := os.Stdout
+	if len(os.Args) > 1 {
+		logOutput = os.Stderr
+	}
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
-	logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+	logger = zerolog.New(logOutput).With().Timestamp().Logger()
 
 	// En desarrollo, usar output legible
 	if os.Getenv("ENV") == "development" {
-		logger = logger.Output(zerolog.ConsoleWriter{Out: os.Stdout})
+		logger = logger.Output(zerolog.ConsoleWriter{Out: logOutput})
 	}
 
 	// Cargar variables de entorno desde .env
@@ -504,11 +655,95 @@ func main() {
 		logger.Info().Msg("No .env file found, using environment variables")
 	}
 
+	// Límites de la Free Tier: los embebidos o los de LIMITS_FILE (YAML/JSON)
+	limitsFile := getEnv("LIMITS_FILE", "")
+	if limitsFile != "" {
+		if err := loadLimitsFile(limitsFile); err != nil {
+			logger.Fatal().Err(err).Msg("Invalid Free Tier limits file")
+		}
+		logger.Info().Str("path", limitsFile).Msg("Free Tier limits loaded from file")
+	}
+
+	// Umbrales de severidad por recurso (fichero y/o variable THRESHOLDS)
+	loaded, err := loadThresholds(getEnv("THRESHOLDS_FILE", ""), getEnv("THRESHOLDS", ""))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Invalid thresholds configuration")
+	}
+	thresholds = loaded
+	logger.Info().
+		Interface("default", thresholds.Default).
+		Strs("overrides", thresholds.overriddenResources()).
+		Msg("Thresholds loaded")
+
+	// Subcomandos del CLI: se ejecutan y salen sin arrancar el servidor
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:], os.Stdout))
+	}
+
 	port := getEnv("PORT", "8088")
 
 	// Validar credenciales de OCI (warn si faltan, no bloquear el inicio)
 	validateEnvVars()
 
+	// Recarga en caliente de los límites (SIGHUP o cambio del fichero)
+	if limitsFile != "" {
+		watchLimitsFile(limitsFile, getEnvDuration("LIMITS_WATCH_INTERVAL", 30*time.Second))
+	}
+
+	// Recolector en segundo plano: /usage y /status sirven el snapshot en caché
+	pollInterval := getEnvDuration("POLL_INTERVAL", 5*time.Minute)
+	poller = newUsagePoller(pollInterval, getOCIUsage)
+
+	// Histórico persistente: cada snapshot recolectado se guarda en bbolt
+	historyPath := getEnv("HISTORY_PATH", "history.db")
+	store, err := openHistoryStore(historyPath,
+		getEnvDuration("HISTORY_RAW_RETENTION", 48*time.Hour),
+		getEnvDuration("HISTORY_HOURLY_RETENTION", 30*24*time.Hour),
+		getEnvDuration("HISTORY_DAILY_RETENTION", 365*24*time.Hour),
+	)
+	if err != nil {
+		logger.Error().Err(err).Str("path", historyPath).Msg("History disabled")
+	} else {
+		history = store
+		poller.OnCollect(func(usage *AllUsage, collectedAt time.Time) {
+			if err := history.Record(usage, collectedAt); err != nil {
+				logger.Error().Err(err).Msg("Error recording usage history")
+			}
+		})
+	}
+
+	// Notificaciones de cambio de estado (solo si hay algún canal configurado)
+	if notifiers := notifiersFromEnv(); len(notifiers) > 0 {
+		notifications := newNotificationManager(notifiers, getEnvDuration("NOTIFY_RENOTIFY_INTERVAL", 12*time.Hour))
+		poller.OnCollect(notifications.HandleUsage)
+		names := make([]string, 0, len(notifiers))
+		for _, n := range notifiers {
+			names = append(names, n.Name())
+		}
+		logger.Info().Strs("notifiers", names).Msg("🔔 Notifications enabled")
+	}
+
+	if isConfigured() {
+		poller.Start(nil)
+		logger.Info().Dur("interval", pollInterval).Msg("Background usage poller started")
+	}
+
+	// Modo keepalive: carga de CPU y memoria para que Oracle no reclame esta instancia
+	if cfg, enabled := keepaliveConfigFromEnv(); enabled {
+		runner := newKeepaliveRunner(cfg)
+		if err := runner.Start(nil); err != nil {
+			logger.Warn().Err(err).Msg("Keepalive disabled")
+		} else {
+			keepalive = runner
+			logger.Info().
+				Float64("cpuTarget", cfg.CPUTarget).
+				Float64("memoryTarget", cfg.MemoryTarget).
+				Float64("dutyCycle", cfg.DutyCycle).
+				Dur("period", cfg.Period).
+				Msg("💓 Keepalive workload enabled")
+		}
+	}
+
 	// Validar API Key
 	apiKey := os.Getenv("API_KEY")
 	if apiKey == "" {
@@ -517,27 +752,40 @@ func main() {
 		logger.Info().Msg("🔒 API authentication enabled")
 	}
 
-	// Registrar los handlers con autenticación
-	http.HandleFunc("/health", authMiddleware(healthHandler))
-	http.HandleFunc("/limits", authMiddleware(limitsHandler))
-	http.HandleFunc("/usage", authMiddleware(usageHandler))
-	http.HandleFunc("/status", authMiddleware(statusHandler))
-
 	// Imprimir información de inicio
 	logger.Info().
 		Str("port", port).
 		Bool("auth_enabled", apiKey != "").
 		Msg("🔍 Oracle Free Tier Watcher started")
-	
+
 	fmt.Printf("📊 Usage endpoint: http://localhost:%s/usage\n", port)
 	fmt.Printf("💚 Health check: http://localhost:%s/health\n", port)
 	fmt.Printf("📋 Limits info: http://localhost:%s/limits\n", port)
 	fmt.Printf("⚡ Quick status: http://localhost:%s/status\n", port)
+	fmt.Printf("📈 History: http://localhost:%s/history?metric=blockStorage.total\n", port)
+	fmt.Printf("📉 Prometheus metrics: http://localhost:%s/metrics\n", port)
+	fmt.Printf("🧭 A1 capacity: http://localhost:%s/capacity\n", port)
+	fmt.Printf("🧮 Headroom plan: http://localhost:%s/plan\n", port)
 
 	if apiKey != "" {
 		fmt.Println("🔒 Authentication required: Add 'X-API-Key' header to requests")
 	}
 
 	// Iniciar el servidor
-	logger.Fatal().Err(http.ListenAndServe(":"+port, nil)).Msg("Server stopped")
+	logger.Fatal().Err(http.ListenAndServe(":"+port, newRouter())).Msg("Server stopped")
+}
+
+// newRouter registra los handlers con autenticación
+// Está separado de main para que los tests end-to-end sirvan exactamente las mismas rutas
+func newRouter() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", authMiddleware(healthHandler))
+	mux.HandleFunc("/limits", authMiddleware(limitsHandler))
+	mux.HandleFunc("/usage", authMiddleware(usageHandler))
+	mux.HandleFunc("/status", authMiddleware(statusHandler))
+	mux.HandleFunc("/history", authMiddleware(historyHandler))
+	mux.HandleFunc("/metrics", authMiddleware(metricsHandler))
+	mux.HandleFunc("/capacity", authMiddleware(capacityHandler))
+	mux.HandleFunc("/plan", authMiddleware(planHandler))
+	return mux
 }
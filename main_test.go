@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"testing"
 )
 
@@ -172,7 +173,7 @@ func BenchmarkGetOCIUsage(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := getOCIUsage()
+		_, err := getOCIUsage(context.Background())
 		if err != nil {
 			b.Fatalf("getOCIUsage() error = %v", err)
 		}
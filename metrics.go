@@ -0,0 +1,226 @@
+// Package main - Este archivo expone las métricas en formato de texto de Prometheus
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// collectorStat acumula las ejecuciones de un colector (getComputeUsage, getBlockStorageUsage...)
+type collectorStat struct {
+	runs         uint64
+	errors       uint64
+	durationSum  float64
+	lastDuration float64
+}
+
+// collectorStats guarda las estadísticas por colector
+// Se protege con un mutex porque los colectores corren en goroutines paralelas
+var collectorStats = struct {
+	sync.Mutex
+	byName map[string]*collectorStat
+}{byName: map[string]*collectorStat{}}
+
+// statusLevels son los valores posibles del estado general (para el gauge tipo enum)
+var statusLevels = []string{SeverityOK, SeverityAttention, SeverityWarning, SeverityDegraded, SeverityCritical, SeverityUnknown}
+
+// recordCollectorRun registra la duración y el resultado de una ejecución de un colector
+func recordCollectorRun(name string, duration time.Duration, failed bool) {
+	collectorStats.Lock()
+	defer collectorStats.Unlock()
+
+	stat, ok := collectorStats.byName[name]
+	if !ok {
+		stat = &collectorStat{}
+		collectorStats.byName[name] = stat
+	}
+	stat.runs++
+	stat.durationSum += duration.Seconds()
+	stat.lastDuration = duration.Seconds()
+	if failed {
+		stat.errors++
+	}
+}
+
+// promLabels formatea un conjunto de labels de Prometheus escapando los valores
+func promLabels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(pairs[i+1])
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, pairs[i], value))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// writeMetricHeader escribe las líneas HELP y TYPE de una familia de métricas
+func writeMetricHeader(w io.Writer, name, help, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+// writeUsageMetrics escribe los gauges used/limit/ratio de cada UsageMetric del snapshot
+func writeUsageMetrics(w io.Writer, usage *AllUsage, region, compartment string) {
+	// Las métricas de colectores que fallaron se omiten: mejor un hueco que un 0 falso
+	metrics := reliableUsageMetrics(usage)
+	paths := make([]string, 0, len(metrics))
+	for path := range metrics {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	families := []struct {
+		name  string
+		help  string
+		value func(UsageMetric) float64
+	}{
+		{"oci_free_tier_usage_used", "Current usage of the resource.", func(m UsageMetric) float64 { return m.Used }},
+		{"oci_free_tier_usage_limit", "Always Free limit of the resource.", func(m UsageMetric) float64 { return m.Limit }},
+		{"oci_free_tier_usage_ratio", "Usage divided by the Always Free limit (1 = 100%).", func(m UsageMetric) float64 {
+			if m.Limit == 0 {
+				return 0
+			}
+			return m.Used / m.Limit
+		}},
+	}
+
+	for _, family := range families {
+		writeMetricHeader(w, family.name, family.help, "gauge")
+		for _, path := range paths {
+			labels := promLabels("resource", path, "region", region, "compartment", compartment)
+			fmt.Fprintf(w, "%s%s %g\n", family.name, labels, family.value(metrics[path]))
+		}
+	}
+}
+
+// writeCollectorMetrics escribe la duración y los errores de cada colector
+func writeCollectorMetrics(w io.Writer) {
+	collectorStats.Lock()
+	defer collectorStats.Unlock()
+
+	names := make([]string, 0, len(collectorStats.byName))
+	for name := range collectorStats.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	writeMetricHeader(w, "oci_watcher_collector_duration_seconds", "Time spent by each OCI collector.", "summary")
+	for _, name := range names {
+		stat := collectorStats.byName[name]
+		labels := promLabels("collector", name)
+		fmt.Fprintf(w, "oci_watcher_collector_duration_seconds_sum%s %g\n", labels, stat.durationSum)
+		fmt.Fprintf(w, "oci_watcher_collector_duration_seconds_count%s %d\n", labels, stat.runs)
+	}
+
+	writeMetricHeader(w, "oci_watcher_collector_last_duration_seconds", "Duration of the last run of each OCI collector.", "gauge")
+	for _, name := range names {
+		fmt.Fprintf(w, "oci_watcher_collector_last_duration_seconds%s %g\n", promLabels("collector", name), collectorStats.byName[name].lastDuration)
+	}
+
+	writeMetricHeader(w, "oci_watcher_collector_errors_total", "Failed runs of each OCI collector.", "counter")
+	for _, name := range names {
+		fmt.Fprintf(w, "oci_watcher_collector_errors_total%s %d\n", promLabels("collector", name), collectorStats.byName[name].errors)
+	}
+}
+
+// writeStatusMetric escribe el estado general como gauge enum (1 en el estado activo)
+func writeStatusMetric(w io.Writer, status string) {
+	writeMetricHeader(w, "oci_watcher_status", "Overall Free Tier status (1 for the current status).", "gauge")
+	for _, level := range statusLevels {
+		value := 0
+		if level == status {
+			value = 1
+		}
+		fmt.Fprintf(w, "oci_watcher_status%s %d\n", promLabels("status", level), value)
+	}
+}
+
+// writeRegionMetrics escribe el uso de cada región (solo con OCI_REGION_SCAN=all)
+func writeRegionMetrics(w io.Writer, usage *AllUsage) {
+	if len(usage.Regions) == 0 {
+		return
+	}
+	writeMetricHeader(w, "oci_free_tier_region_usage_used", "Current usage of the resource in each subscribed region.", "gauge")
+	for _, region := range usage.Regions {
+		paths := make([]string, 0, len(region.Used))
+		for path := range region.Used {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		home := "false"
+		if region.Home {
+			home = "true"
+		}
+		for _, path := range paths {
+			labels := promLabels("resource", path, "region", region.Region, "home", home)
+			fmt.Fprintf(w, "oci_free_tier_region_usage_used%s %g\n", labels, region.Used[path])
+		}
+	}
+}
+
+// writeCompartmentMetrics escribe el uso de cada compartimento (solo con OCI_COMPARTMENT_SCAN=recursive)
+// El total lleva en compartment la raíz del escaneo; estas series desglosan ese total
+func writeCompartmentMetrics(w io.Writer, usage *AllUsage) {
+	if len(usage.Compartments) == 0 {
+		return
+	}
+	writeMetricHeader(w, "oci_free_tier_compartment_usage_used", "Current usage of the resource in each scanned compartment.", "gauge")
+	for _, compartment := range usage.Compartments {
+		paths := make([]string, 0, len(compartment.Used))
+		for path := range compartment.Used {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			labels := promLabels("resource", path, "compartment", compartment.ID, "path", compartment.Path)
+			fmt.Fprintf(w, "oci_free_tier_compartment_usage_used%s %g\n", labels, compartment.Used[path])
+		}
+	}
+}
+
+// metricsHandler maneja GET /metrics (formato de texto de Prometheus)
+// Usa el snapshot en caché: un scrape nunca dispara llamadas a OCI si ya hay datos
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	configured := 0
+	if isConfigured() {
+		configured = 1
+	}
+	writeMetricHeader(w, "oci_watcher_configured", "Whether OCI credentials are configured.", "gauge")
+	fmt.Fprintf(w, "oci_watcher_configured %d\n", configured)
+
+	if configured == 1 && poller != nil {
+		usage, collectedAt, err := poller.Snapshot(r.Context(), false)
+		if err == nil {
+			eval := evaluateUsage(usage)
+			writeStatusMetric(w, eval.Status)
+
+			writeMetricHeader(w, "oci_watcher_max_usage_percentage", "Highest usage percentage across resources.", "gauge")
+			fmt.Fprintf(w, "oci_watcher_max_usage_percentage %d\n", eval.MaxPercentage)
+
+			writeMetricHeader(w, "oci_watcher_snapshot_age_seconds", "Age of the cached usage snapshot.", "gauge")
+			fmt.Fprintf(w, "oci_watcher_snapshot_age_seconds %d\n", snapshotAge(collectedAt))
+
+			// En modo multi-región el total es el de la región principal
+			region := getRegion()
+			if home := homeRegion(usage); home != "" {
+				region = home
+			}
+			writeUsageMetrics(w, usage, region, getCompartmentID())
+			writeRegionMetrics(w, usage)
+			writeCompartmentMetrics(w, usage)
+		}
+	}
+
+	writeCollectorMetrics(w)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setTestOCIEnv configura credenciales falsas para que isConfigured() devuelva true
+func setTestOCIEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("OCI_TENANCY_ID", "ocid1.tenancy.test")
+	t.Setenv("OCI_USER_ID", "ocid1.user.test")
+	t.Setenv("OCI_FINGERPRINT", "aa:bb:cc:dd")
+	t.Setenv("OCI_PRIVATE_KEY_PATH", "/tmp/test.pem")
+	t.Setenv("OCI_REGION", "eu-madrid-1")
+	t.Setenv("OCI_COMPARTMENT_ID", "")
+}
+
+// TestMetricsHandler verifica el formato de exposición de Prometheus
+func TestMetricsHandler(t *testing.T) {
+	setTestOCIEnv(t)
+
+	usage := &AllUsage{}
+	usage.BlockStorage.Total = UsageMetric{Used: 190, Limit: 200, Percentage: 95}
+	usage.Compartments = []CompartmentUsage{
+		{ID: "ocid1.tenancy.test", Path: "/", Used: map[string]float64{"blockStorage.total": 90}},
+		{ID: "ocid1.compartment.prod", Name: "prod", Path: "/prod", Used: map[string]float64{"blockStorage.total": 100}},
+	}
+	poller = newUsagePoller(time.Minute, func(ctx context.Context) (*AllUsage, error) { return usage, nil })
+	defer func() { poller = nil }()
+
+	recordCollectorRun("compute", 250*time.Millisecond, true)
+
+	rec := httptest.NewRecorder()
+	metricsHandler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; want 200", rec.Code)
+	}
+	body := rec.Body.String()
+
+	want := []string{
+		"oci_watcher_configured 1",
+		`oci_free_tier_usage_used{resource="blockStorage.total",region="eu-madrid-1",compartment="ocid1.tenancy.test"} 190`,
+		`oci_free_tier_usage_limit{resource="blockStorage.total",region="eu-madrid-1",compartment="ocid1.tenancy.test"} 200`,
+		`oci_free_tier_usage_ratio{resource="blockStorage.total",region="eu-madrid-1",compartment="ocid1.tenancy.test"} 0.95`,
+		`oci_free_tier_compartment_usage_used{resource="blockStorage.total",compartment="ocid1.compartment.prod",path="/prod"} 100`,
+		`oci_free_tier_compartment_usage_used{resource="blockStorage.total",compartment="ocid1.tenancy.test",path="/"} 90`,
+		`oci_watcher_status{status="CRITICAL"} 1`,
+		`oci_watcher_status{status="OK"} 0`,
+		`oci_watcher_collector_errors_total{collector="compute"}`,
+		"# TYPE oci_watcher_collector_duration_seconds summary",
+	}
+	for _, line := range want {
+		if !strings.Contains(body, line) {
+			t.Errorf("metrics output missing %q\n%s", line, body)
+		}
+	}
+}
+
+// TestPromLabelsEscaping verifica que los valores de los labels se escapan
+func TestPromLabelsEscaping(t *testing.T) {
+	got := promLabels("name", `a"b\c`)
+	want := `{name="a\"b\\c"}`
+	if got != want {
+		t.Errorf("promLabels() = %s; want %s", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+// Package main - Este archivo contiene las consultas comunes a OCI Monitoring
+// Los límites mensuales (tráfico de salida, peticiones de Object Storage) se miden
+// acumulando las métricas desde el día 1 del mes
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+)
+
+// monthStart devuelve el comienzo (UTC) del mes de now
+func monthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// projectToMonthEnd extrapola lo acumulado en el mes al mes completo al ritmo actual
+func projectToMonthEnd(value float64, now time.Time) float64 {
+	start := monthStart(now)
+	elapsed := now.Sub(start)
+	if elapsed <= 0 {
+		return value
+	}
+	month := start.AddDate(0, 1, 0).Sub(start)
+	return value * float64(month) / float64(elapsed)
+}
+
+// monthToDateSeries ejecuta una consulta MQL diaria desde el día 1 del mes hasta now
+// La consulta debe usar un intervalo [1d]: con resolución 1d hay un punto por día
+// sin ventanas solapadas, así que sumar los puntos da el total del mes
+func monthToDateSeries(ctx context.Context, client monitoringAPI, compartmentID, namespace, query string, now time.Time) ([]monitoring.MetricData, error) {
+	return metricSeries(ctx, client, compartmentID, namespace, query, "1d", monthStart(now), now)
+}
+
+// metricSeries ejecuta una consulta MQL entre start y end
+// Devuelve una serie por recurso (dimensión resourceId o resourceID)
+func metricSeries(ctx context.Context, client monitoringAPI, compartmentID, namespace, query, resolution string, start, end time.Time) ([]monitoring.MetricData, error) {
+	response, err := client.SummarizeMetricsData(ctx, monitoring.SummarizeMetricsDataRequest{
+		CompartmentId: common.String(compartmentID),
+		SummarizeMetricsDataDetails: monitoring.SummarizeMetricsDataDetails{
+			Namespace:  common.String(namespace),
+			Query:      common.String(query),
+			StartTime:  &common.SDKTime{Time: start},
+			EndTime:    &common.SDKTime{Time: end},
+			Resolution: common.String(resolution),
+		},
+	})
+	if err != nil {
+		return nil, ociError("monitoring", "SummarizeMetricsData", err)
+	}
+	return response.Items, nil
+}
+
+// sumDatapoints suma todos los puntos de una serie
+func sumDatapoints(series monitoring.MetricData) float64 {
+	var total float64
+	for _, point := range series.AggregatedDatapoints {
+		if point.Value != nil {
+			total += *point.Value
+		}
+	}
+	return total
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestProjectToMonthEnd(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		now   time.Time
+		want  float64
+	}{
+		{"mitad de un mes de 30 días", 2, time.Date(2026, 11, 16, 0, 0, 0, 0, time.UTC), 4},
+		{"primer día completo de un mes de 31", 1, time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), 31},
+		{"justo al empezar el mes no se extrapola", 3, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), 3},
+		{"otra zona horaria usa el mes en UTC", 1, time.Date(2026, 10, 2, 2, 0, 0, 0, time.FixedZone("CEST", 2*3600)), 31},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := projectToMonthEnd(tt.value, tt.now); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("projectToMonthEnd(%v, %s) = %v; want %v", tt.value, tt.now, got, tt.want)
+			}
+		})
+	}
+}
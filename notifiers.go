@@ -0,0 +1,248 @@
+// Package main - Este archivo contiene los canales de notificación
+// (webhook genérico, Slack, Discord, Telegram, email SMTP y ntfy)
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// notifyHTTPClient es el cliente HTTP compartido por los notificadores
+var notifyHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// postJSON envía payload como JSON y devuelve error si la respuesta no es 2xx
+func postJSON(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doNotifyRequest(req)
+}
+
+// doNotifyRequest ejecuta la petición y convierte los códigos no 2xx en error
+func doNotifyRequest(req *http.Request) error {
+	resp, err := notifyHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// WebhookNotifier envía la notificación completa como JSON a cualquier URL
+type WebhookNotifier struct {
+	URL string
+}
+
+// Name identifica el canal en los logs
+func (n *WebhookNotifier) Name() string { return "webhook" }
+
+// Notify envía la notificación
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	return postJSON(ctx, n.URL, struct {
+		Notification
+		Title string `json:"title"`
+		Text  string `json:"text"`
+	}{notification, notification.Title(), notification.Text()})
+}
+
+// SlackNotifier usa un Incoming Webhook de Slack
+type SlackNotifier struct {
+	WebhookURL string
+}
+
+// Name identifica el canal en los logs
+func (n *SlackNotifier) Name() string { return "slack" }
+
+// Notify envía la notificación
+func (n *SlackNotifier) Notify(ctx context.Context, notification Notification) error {
+	return postJSON(ctx, n.WebhookURL, map[string]string{"text": notification.Text()})
+}
+
+// DiscordNotifier usa un webhook de canal de Discord
+type DiscordNotifier struct {
+	WebhookURL string
+}
+
+// Name identifica el canal en los logs
+func (n *DiscordNotifier) Name() string { return "discord" }
+
+// Notify envía la notificación
+func (n *DiscordNotifier) Notify(ctx context.Context, notification Notification) error {
+	return postJSON(ctx, n.WebhookURL, map[string]string{"content": notification.Text()})
+}
+
+// TelegramNotifier usa la Bot API de Telegram (sendMessage)
+type TelegramNotifier struct {
+	BotToken string
+	ChatID   string
+	// APIURL permite cambiar el endpoint (por defecto https://api.telegram.org)
+	APIURL string
+}
+
+// Name identifica el canal en los logs
+func (n *TelegramNotifier) Name() string { return "telegram" }
+
+// Notify envía la notificación
+func (n *TelegramNotifier) Notify(ctx context.Context, notification Notification) error {
+	apiURL := n.APIURL
+	if apiURL == "" {
+		apiURL = "https://api.telegram.org"
+	}
+	return postJSON(ctx, fmt.Sprintf("%s/bot%s/sendMessage", apiURL, n.BotToken), map[string]string{
+		"chat_id": n.ChatID,
+		"text":    notification.Text(),
+	})
+}
+
+// EmailNotifier envía un email mediante SMTP (STARTTLS si el servidor lo ofrece)
+type EmailNotifier struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Name identifica el canal en los logs
+func (n *EmailNotifier) Name() string { return "email" }
+
+// Notify envía la notificación
+// net/smtp no acepta context: la conexión se abre con el context y hereda su deadline,
+// así un servidor que no responde corta el envío en lugar de dejar una goroutine colgada
+func (n *EmailNotifier) Notify(ctx context.Context, notification Notification) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", notification.Title()))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(notification.Text(), "\n", "\r\n"))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.Host, n.Port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	// Si el context se cancela sin deadline, cerrar la conexión desbloquea la lectura en curso
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := n.send(conn, msg.Bytes()); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// send recorre la conversación SMTP sobre una conexión ya abierta
+// Repite lo que hace smtp.SendMail: STARTTLS si el servidor lo ofrece y AUTH si hay usuario
+func (n *EmailNotifier) send(conn net.Conn, msg []byte) error {
+	c, err := smtp.NewClient(conn, n.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.Host}); err != nil {
+			return err
+		}
+	}
+	if n.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(smtp.PlainAuth("", n.Username, n.Password, n.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.From); err != nil {
+		return err
+	}
+	for _, to := range n.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// NtfyNotifier publica en un topic de ntfy (ej. https://ntfy.sh/mi-topic)
+type NtfyNotifier struct {
+	URL   string
+	Token string
+}
+
+// Name identifica el canal en los logs
+func (n *NtfyNotifier) Name() string { return "ntfy" }
+
+// Notify envía la notificación
+func (n *NtfyNotifier) Notify(ctx context.Context, notification Notification) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, strings.NewReader(notification.Text()))
+	if err != nil {
+		return err
+	}
+
+	priority := "default"
+	tags := "information_source"
+	switch {
+	case notification.Resolved:
+		tags = "white_check_mark"
+	case notification.Status == SeverityCritical:
+		priority, tags = "urgent", "rotating_light"
+	case notification.Status == SeverityWarning:
+		priority, tags = "high", "warning"
+	case notification.Status == SeverityDegraded, notification.Status == SeverityUnknown:
+		priority, tags = "high", "question"
+	}
+
+	// Los headers HTTP deben ir en ASCII: el título (con emoji) se codifica en RFC 2047
+	req.Header.Set("Title", mime.BEncoding.Encode("UTF-8", notification.Title()))
+	req.Header.Set("Priority", priority)
+	req.Header.Set("Tags", tags)
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+	return doNotifyRequest(req)
+}
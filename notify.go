@@ -0,0 +1,216 @@
+// Package main - Este archivo detecta cambios de estado y envía notificaciones
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notification describe un cambio de estado (o un recordatorio) a notificar
+type Notification struct {
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previousStatus,omitempty"`
+	MaxPercentage  int       `json:"maxUsagePercentage"`
+	Warnings       []string  `json:"warnings"`
+	Resolved       bool      `json:"resolved"`
+	Reminder       bool      `json:"reminder"`
+	Timestamp      time.Time `json:"timestamp"`
+	// Event y Summary marcan avisos que no son cambios de estado (p. ej. "instance.launched"):
+	// Summary es el título y Warnings lleva los detalles
+	Event   string `json:"event,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// Title devuelve un resumen de una línea de la notificación
+func (n Notification) Title() string {
+	switch {
+	case n.Summary != "":
+		return n.Summary
+	case n.Resolved:
+		return fmt.Sprintf("✅ Oracle Free Tier back to OK (was %s)", n.PreviousStatus)
+	case n.Reminder:
+		return fmt.Sprintf("🔁 Oracle Free Tier still %s (%d%%)", n.Status, n.MaxPercentage)
+	default:
+		return fmt.Sprintf("%s Oracle Free Tier status %s (%d%%)", statusEmoji(n.Status), n.Status, n.MaxPercentage)
+	}
+}
+
+// Text devuelve el cuerpo de la notificación en texto plano
+func (n Notification) Text() string {
+	var b strings.Builder
+	b.WriteString(n.Title())
+	b.WriteString("\n")
+	if n.Event != "" {
+		for _, line := range n.Warnings {
+			fmt.Fprintf(&b, "%s\n", line)
+		}
+		fmt.Fprintf(&b, "Time: %s", n.Timestamp.UTC().Format(time.RFC3339))
+		return b.String()
+	}
+	if n.PreviousStatus != "" && !n.Resolved && !n.Reminder {
+		fmt.Fprintf(&b, "Previous status: %s\n", n.PreviousStatus)
+	}
+	fmt.Fprintf(&b, "Max usage: %d%%\n", n.MaxPercentage)
+	for _, w := range n.Warnings {
+		fmt.Fprintf(&b, "- %s\n", w)
+	}
+	fmt.Fprintf(&b, "Time: %s", n.Timestamp.UTC().Format(time.RFC3339))
+	return b.String()
+}
+
+// statusEmoji asocia un emoji a cada estado para los mensajes
+func statusEmoji(status string) string {
+	switch status {
+	case SeverityUnknown:
+		return "❓"
+	case SeverityCritical:
+		return "🚨"
+	case SeverityDegraded:
+		return "🔧"
+	case SeverityWarning:
+		return "⚠️"
+	case SeverityAttention:
+		return "👀"
+	default:
+		return "ℹ️"
+	}
+}
+
+// Notifier es la interfaz que implementa cada canal de notificación
+// En Go las interfaces se satisfacen implícitamente: basta con tener estos métodos
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NotificationManager sigue el estado entre recolecciones y decide cuándo notificar
+// - Notifica cada transición entre OK/ATTENTION/WARNING/CRITICAL (sin duplicados)
+// - Repite el aviso cada renotifyInterval mientras siga en CRITICAL
+// - Envía un mensaje de "resolved" al volver a OK
+type NotificationManager struct {
+	notifiers        []Notifier
+	renotifyInterval time.Duration
+	timeout          time.Duration
+
+	mu           sync.Mutex
+	lastStatus   string
+	lastNotified time.Time
+}
+
+// newNotificationManager crea un gestor con los canales indicados
+func newNotificationManager(notifiers []Notifier, renotifyInterval time.Duration) *NotificationManager {
+	return &NotificationManager{
+		notifiers:        notifiers,
+		renotifyInterval: renotifyInterval,
+		timeout:          15 * time.Second,
+	}
+}
+
+// Observe recibe el estado de una recolección y devuelve la notificación a enviar (o nil)
+func (m *NotificationManager) Observe(status string, maxPercentage int, warnings []string, at time.Time) *Notification {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous := m.lastStatus
+	m.lastStatus = status
+
+	n := &Notification{
+		Status:        status,
+		MaxPercentage: maxPercentage,
+		Warnings:      warnings,
+		Timestamp:     at,
+	}
+
+	switch {
+	case previous == "" && status == SeverityOK:
+		// Primer arranque sin problemas: nada que avisar
+		return nil
+	case previous == status:
+		if status != SeverityCritical || at.Sub(m.lastNotified) < m.renotifyInterval {
+			return nil
+		}
+		n.Reminder = true
+	case status == SeverityOK:
+		n.PreviousStatus = previous
+		n.Resolved = true
+	default:
+		n.PreviousStatus = previous
+	}
+
+	m.lastNotified = at
+	return n
+}
+
+// Dispatch envía una notificación por todos los canales configurados
+func (m *NotificationManager) Dispatch(n Notification) {
+	for _, notifier := range m.notifiers {
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		err := notifier.Notify(ctx, n)
+		cancel()
+		if err != nil {
+			logger.Error().Err(err).Str("notifier", notifier.Name()).Msg("Error sending notification")
+			continue
+		}
+		logger.Info().
+			Str("notifier", notifier.Name()).
+			Str("status", n.Status).
+			Bool("resolved", n.Resolved).
+			Msg("Notification sent")
+	}
+}
+
+// HandleUsage evalúa un snapshot y notifica en segundo plano si corresponde
+// Se registra como listener del poller
+func (m *NotificationManager) HandleUsage(usage *AllUsage, collectedAt time.Time) {
+	eval := evaluateUsage(usage)
+	if n := m.Observe(eval.Status, eval.MaxPercentage, eval.Warnings(), collectedAt); n != nil {
+		go m.Dispatch(*n)
+	}
+}
+
+// notifiersFromEnv construye los canales configurados mediante variables de entorno
+func notifiersFromEnv() []Notifier {
+	var notifiers []Notifier
+
+	if url := getEnv("NOTIFY_WEBHOOK_URL", ""); url != "" {
+		notifiers = append(notifiers, &WebhookNotifier{URL: url})
+	}
+	if url := getEnv("NOTIFY_SLACK_WEBHOOK_URL", ""); url != "" {
+		notifiers = append(notifiers, &SlackNotifier{WebhookURL: url})
+	}
+	if url := getEnv("NOTIFY_DISCORD_WEBHOOK_URL", ""); url != "" {
+		notifiers = append(notifiers, &DiscordNotifier{WebhookURL: url})
+	}
+	if token, chatID := getEnv("NOTIFY_TELEGRAM_BOT_TOKEN", ""), getEnv("NOTIFY_TELEGRAM_CHAT_ID", ""); token != "" && chatID != "" {
+		notifiers = append(notifiers, &TelegramNotifier{BotToken: token, ChatID: chatID})
+	}
+	if host, to := getEnv("NOTIFY_SMTP_HOST", ""), getEnv("NOTIFY_SMTP_TO", ""); host != "" && to != "" {
+		notifiers = append(notifiers, &EmailNotifier{
+			Host:     host,
+			Port:     getEnv("NOTIFY_SMTP_PORT", "587"),
+			Username: getEnv("NOTIFY_SMTP_USERNAME", ""),
+			Password: getEnv("NOTIFY_SMTP_PASSWORD", ""),
+			From:     getEnv("NOTIFY_SMTP_FROM", getEnv("NOTIFY_SMTP_USERNAME", "")),
+			To:       splitList(to),
+		})
+	}
+	if url := getEnv("NOTIFY_NTFY_URL", ""); url != "" {
+		notifiers = append(notifiers, &NtfyNotifier{URL: url, Token: getEnv("NOTIFY_NTFY_TOKEN", "")})
+	}
+
+	return notifiers
+}
+
+// splitList separa una lista de valores separados por comas ignorando los vacíos
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
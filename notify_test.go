@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestNotificationManagerTransitions verifica la deduplicación, los recordatorios y el "resolved"
+func TestNotificationManagerTransitions(t *testing.T) {
+	m := newNotificationManager(nil, time.Hour)
+	base := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name         string
+		status       string
+		after        time.Duration
+		wantNotify   bool
+		wantResolved bool
+		wantReminder bool
+		wantPrevious string
+	}{
+		{name: "arranque en OK no notifica", status: "OK", after: 0},
+		{name: "OK a WARNING notifica", status: "WARNING", after: time.Minute, wantNotify: true, wantPrevious: "OK"},
+		{name: "WARNING repetido se deduplica", status: "WARNING", after: 2 * time.Hour},
+		{name: "WARNING a CRITICAL notifica", status: "CRITICAL", after: 3 * time.Hour, wantNotify: true, wantPrevious: "WARNING"},
+		{name: "CRITICAL antes del intervalo no repite", status: "CRITICAL", after: 3*time.Hour + 30*time.Minute},
+		{name: "CRITICAL tras el intervalo recuerda", status: "CRITICAL", after: 4 * time.Hour, wantNotify: true, wantReminder: true},
+		{name: "vuelta a OK envía resolved", status: "OK", after: 5 * time.Hour, wantNotify: true, wantResolved: true, wantPrevious: "CRITICAL"},
+		{name: "OK repetido no notifica", status: "OK", after: 6 * time.Hour},
+	}
+
+	for _, tt := range steps {
+		t.Run(tt.name, func(t *testing.T) {
+			n := m.Observe(tt.status, 0, nil, base.Add(tt.after))
+			if (n != nil) != tt.wantNotify {
+				t.Fatalf("Observe(%s) notify = %v; want %v", tt.status, n != nil, tt.wantNotify)
+			}
+			if n == nil {
+				return
+			}
+			if n.Resolved != tt.wantResolved || n.Reminder != tt.wantReminder || n.PreviousStatus != tt.wantPrevious {
+				t.Errorf("notification = %+v; want resolved=%v reminder=%v previous=%q",
+					n, tt.wantResolved, tt.wantReminder, tt.wantPrevious)
+			}
+		})
+	}
+}
+
+// TestWebhookNotifiers verifica el payload que envía cada canal basado en webhooks
+func TestWebhookNotifiers(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = nil
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid JSON body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	notification := Notification{
+		Status:         "CRITICAL",
+		PreviousStatus: "WARNING",
+		MaxPercentage:  95,
+		Warnings:       []string{"Block Storage at 95%"},
+		Timestamp:      time.Now(),
+	}
+
+	tests := []struct {
+		notifier Notifier
+		field    string
+	}{
+		{notifier: &WebhookNotifier{URL: server.URL}, field: "status"},
+		{notifier: &SlackNotifier{WebhookURL: server.URL}, field: "text"},
+		{notifier: &DiscordNotifier{WebhookURL: server.URL}, field: "content"},
+		{notifier: &TelegramNotifier{BotToken: "token", ChatID: "42", APIURL: server.URL}, field: "chat_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.notifier.Name(), func(t *testing.T) {
+			if err := tt.notifier.Notify(context.Background(), notification); err != nil {
+				t.Fatalf("Notify() error = %v", err)
+			}
+			if _, ok := got[tt.field]; !ok {
+				t.Errorf("payload %v missing field %q", got, tt.field)
+			}
+		})
+	}
+}
+
+// TestNotifierHTTPError verifica que una respuesta no 2xx se reporta como error
+func TestNotifierHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	n := &NtfyNotifier{URL: server.URL}
+	if err := n.Notify(context.Background(), Notification{Status: "WARNING"}); err == nil {
+		t.Error("Notify() error = nil; want error on 403")
+	}
+}
+
+// TestEmailNotifierTimeout verifica que un servidor SMTP que no responde corta el envío
+// con el deadline del context y cierra la conexión
+func TestEmailNotifierTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Nunca envía el saludo 220: el cliente se queda esperando hasta que cierra
+		io.Copy(io.Discard, conn)
+		close(closed)
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	n := &EmailNotifier{Host: host, Port: port, From: "watcher@example.com", To: []string{"ops@example.com"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := n.Notify(ctx, Notification{Status: "WARNING"}); err == nil {
+		t.Fatal("Notify() error = nil; want timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Notify() took %s; want to return at the deadline", elapsed)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Error("connection still open after the timeout")
+	}
+}
@@ -3,43 +3,34 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/oracle/oci-go-sdk/v65/common"
 	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/database"
 	"github.com/oracle/oci-go-sdk/v65/loadbalancer"
 	"github.com/oracle/oci-go-sdk/v65/objectstorage"
 )
 
-// createConfigProvider crea el proveedor de autenticación de OCI
+// createConfigProvider crea el proveedor de autenticación de OCI según OCI_AUTH_MODE
 // En Go, los errores se devuelven como segundo valor (no se lanzan excepciones)
 func createConfigProvider() (common.ConfigurationProvider, error) {
-	tenancy := os.Getenv("OCI_TENANCY_ID")
-	user := os.Getenv("OCI_USER_ID")
-	fingerprint := os.Getenv("OCI_FINGERPRINT")
-	privateKeyPath := os.Getenv("OCI_PRIVATE_KEY_PATH")
-	region := os.Getenv("OCI_REGION")
-
-	// Leer la clave privada desde el archivo
-	privateKeyBytes, err := os.ReadFile(privateKeyPath)
-	if err != nil {
-		return nil, fmt.Errorf("error reading private key: %w", err)
-	}
-
-	// Crear el proveedor de configuración
-	// common.NewRawConfigurationProvider es una función del SDK de OCI
-	provider := common.NewRawConfigurationProvider(
-		tenancy,
-		user,
-		region,
-		fingerprint,
-		string(privateKeyBytes),
-		nil, // passphrase (nil si no tiene)
-	)
-
-	return provider, nil
+	switch mode := currentAuthMode(); mode {
+	case AuthModeAPIKey:
+		return apiKeyProvider()
+	case AuthModeInstancePrincipal:
+		return instancePrincipalProvider()
+	case AuthModeConfigFile:
+		return configFileProvider(false)
+	case AuthModeSecurityToken:
+		return configFileProvider(true)
+	default:
+		return nil, fmt.Errorf("unknown OCI_AUTH_MODE %q (valid: %s)", mode, strings.Join(authModes, ", "))
+	}
 }
 
 // getCompartmentID obtiene el ID del compartimento a monitorear
@@ -47,21 +38,77 @@ func getCompartmentID() string {
 	compartmentID := os.Getenv("OCI_COMPARTMENT_ID")
 	if compartmentID == "" {
 		// Si no hay compartimento específico, usar el tenancy (root)
-		return os.Getenv("OCI_TENANCY_ID")
+		return getTenancyID()
 	}
 	return compartmentID
 }
 
 // getOCIUsage obtiene todo el uso de OCI de forma paralela
 // Usa goroutines para hacer las llamadas a la API de OCI concurrentemente
-func getOCIUsage() (*AllUsage, error) {
+// ctx permite cancelar la recolección; además se aplican los timeouts de OCI_TIMEOUT*
+func getOCIUsage(ctx context.Context) (*AllUsage, error) {
 	provider, err := createConfigProvider()
 	if err != nil {
 		return nil, err
 	}
 
-	compartmentID := getCompartmentID()
+	pagination := currentPagination()
+	timeouts := currentTimeouts()
+
+	// Tope global: ninguna recolección puede tardar más que OCI_TIMEOUT
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Global)
+	defer cancel()
+
+	// Compartimentos a recorrer: uno solo o todo el árbol (OCI_COMPARTMENT_SCAN)
+	backend := newOCIBackend(provider)
+	compartments, err := resolveCompartments(ctx, backend, pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	// Regiones a recolectar: OCI_REGION o todas las suscritas (OCI_REGION_SCAN)
+	region, _ := provider.Region()
+	regions, err := resolveRegions(ctx, backend, region)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := collectRegions(ctx, provider, regions, compartments, pagination, timeouts)
+
+	// Si quien pidió los datos canceló (no es un timeout), el resultado no sirve
+	if ctx.Err() == context.Canceled {
+		return nil, ctx.Err()
+	}
 
+	// Avisar si algún colector alcanzó el tope de páginas, su timeout o falló
+	if len(usage.TimedOut) > 0 {
+		logger.Warn().Strs("collectors", usage.TimedOut).Msg("OCI collectors timed out")
+	}
+	for _, e := range usage.Errors {
+		logger.Error().
+			Str("collector", e.Collector).
+			Str("compartment", e.Compartment).
+			Str("region", e.Region).
+			Str("service", e.Service).
+			Str("operation", e.Operation).
+			Int("http_status", e.HTTPStatus).
+			Str("opc_request_id", e.OpcRequestID).
+			Str("error", e.Message).
+			Msg("OCI collector failed")
+	}
+	if len(usage.Truncated) > 0 {
+		logger.Warn().
+			Strs("collectors", usage.Truncated).
+			Int("max_pages", pagination.MaxPages).
+			Msg("Pagination safety cap reached, usage may be undercounted")
+	}
+
+	return usage, nil
+}
+
+// collectCompartment obtiene el uso de un único compartimento
+// Lanza los cinco colectores en paralelo, cada uno con su timeout
+func collectCompartment(ctx context.Context, backend ociBackend, compartmentID string, pagination paginationConfig, timeouts timeoutConfig) *AllUsage {
 	// Usar goroutines para obtener datos en paralelo
 	// Esto reduce el tiempo de respuesta significativamente
 	var (
@@ -69,251 +116,456 @@ func getOCIUsage() (*AllUsage, error) {
 		blockStorageUsage  StorageUsage
 		objectStorageUsage ObjectStorageUsage
 		loadBalancerUsage  LoadBalancerUsage
+		databaseUsage      DatabaseUsage
+		bandwidthUsage     BandwidthUsage
+		reclamationUsage   ReclamationUsage
 		publicIPUsage      UsageMetric
+		publicIPTruncated  bool
+		// objectStorageRequests son las peticiones del mes por bucket hasta requestsAt
+		objectStorageRequests map[string]float64
+		requestsAt            = time.Now()
 	)
 
-	// Canal para sincronización (esperamos 5 goroutines)
-	done := make(chan bool, 5)
+	// Canal para sincronización: cada goroutine envía el resultado de su colector
+	type collectorResult struct {
+		name     string
+		err      error
+		timedOut bool
+	}
+	done := make(chan collectorResult, len(collectorNames))
+
+	// run lanza un colector con su propio timeout y registra sus estadísticas
+	run := func(name string, collect func(ctx context.Context) error) {
+		go func() {
+			collectorCtx, cancel := context.WithTimeout(ctx, timeouts.For(name))
+			defer cancel()
+
+			start := time.Now()
+			err := collect(collectorCtx)
+			timedOut := isTimeout(collectorCtx)
+			if err == nil && timedOut {
+				err = fmt.Errorf("timed out after %s: %w", timeouts.For(name), context.DeadlineExceeded)
+			}
+			recordCollectorRun(name, time.Since(start), err != nil)
+			done <- collectorResult{name: name, err: err, timedOut: timedOut}
+		}()
+	}
 
 	// Lanzar todas las consultas en paralelo
-	go func() {
-		computeUsage = getComputeUsage(provider, compartmentID)
-		done <- true
-	}()
-
-	go func() {
-		blockStorageUsage = getBlockStorageUsage(provider, compartmentID)
-		done <- true
-	}()
-
-	go func() {
-		objectStorageUsage = getObjectStorageUsage(provider, compartmentID)
-		done <- true
-	}()
-
-	go func() {
-		loadBalancerUsage = getLoadBalancerUsage(provider, compartmentID)
-		done <- true
-	}()
-
-	go func() {
-		publicIPUsage = getPublicIPsUsage(provider, compartmentID)
-		done <- true
-	}()
+	// reclamation necesita las instancias de compute: espera a computeDone
+	computeDone := make(chan struct{})
+	run("compute", func(ctx context.Context) (err error) {
+		defer close(computeDone)
+		computeUsage, err = getComputeUsage(ctx, backend, compartmentID, pagination)
+		return err
+	})
+	run("blockStorage", func(ctx context.Context) (err error) {
+		blockStorageUsage, err = getBlockStorageUsage(ctx, backend, compartmentID, pagination)
+		return err
+	})
+	run("objectStorage", func(ctx context.Context) (err error) {
+		objectStorageUsage, err = getObjectStorageUsage(ctx, backend, compartmentID, pagination)
+		return err
+	})
+	// Las peticiones salen de Monitoring: un tenancy sin permiso de lectura de métricas
+	// pierde solo este colector, no el tamaño de los buckets
+	run("objectStorageRequests", func(ctx context.Context) (err error) {
+		objectStorageRequests, err = getObjectStorageRequests(ctx, backend, compartmentID, requestsAt)
+		return err
+	})
+	run("loadBalancer", func(ctx context.Context) (err error) {
+		loadBalancerUsage, err = getLoadBalancerUsage(ctx, backend, compartmentID, pagination)
+		return err
+	})
+	run("database", func(ctx context.Context) (err error) {
+		databaseUsage, err = getDatabaseUsage(ctx, backend, compartmentID, pagination)
+		return err
+	})
+	run("bandwidth", func(ctx context.Context) (err error) {
+		bandwidthUsage, err = getBandwidthUsage(ctx, backend, compartmentID, time.Now())
+		return err
+	})
+	run("reclamation", func(ctx context.Context) (err error) {
+		select {
+		case <-computeDone:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		reclamationUsage, err = getReclamationRisk(ctx, backend, compartmentID, computeUsage.Instances, time.Now())
+		return err
+	})
+	run("publicIPs", func(ctx context.Context) (err error) {
+		publicIPUsage, publicIPTruncated, err = getPublicIPsUsage(ctx, backend, compartmentID, pagination)
+		return err
+	})
 
 	// Esperar a que todas las goroutines terminen
-	for i := 0; i < 5; i++ {
-		<-done
+	results := map[string]collectorResult{}
+	for range collectorNames {
+		result := <-done
+		results[result.name] = result
 	}
 
-	return &AllUsage{
+	applyObjectStorageRequests(&objectStorageUsage, objectStorageRequests, requestsAt)
+
+	usage := &AllUsage{
 		Compute:       computeUsage,
 		BlockStorage:  blockStorageUsage,
 		PublicIPs:     publicIPUsage,
 		ObjectStorage: objectStorageUsage,
 		LoadBalancer:  loadBalancerUsage,
-	}, nil
+		Database:      databaseUsage,
+		Bandwidth:     bandwidthUsage,
+		Reclamation:   reclamationUsage,
+	}
+
+	truncated := map[string]bool{
+		"compute":       computeUsage.Truncated,
+		"blockStorage":  blockStorageUsage.Truncated,
+		"objectStorage": objectStorageUsage.Truncated,
+		"loadBalancer":  loadBalancerUsage.Truncated,
+		"database":      databaseUsage.Truncated,
+		"publicIPs":     publicIPTruncated,
+	}
+	for _, name := range collectorNames {
+		if truncated[name] {
+			usage.Truncated = append(usage.Truncated, name)
+		}
+		if results[name].timedOut {
+			usage.TimedOut = append(usage.TimedOut, name)
+		}
+		if err := results[name].err; err != nil {
+			collectorErr := newCollectorError(name, err)
+			collectorErr.TimedOut = collectorErr.TimedOut || results[name].timedOut
+			usage.Errors = append(usage.Errors, collectorErr)
+		}
+	}
+
+	return usage
 }
 
 // getPublicIPsUsage monitoriza las IPs públicas reservadas (límite free tier: 2)
-func getPublicIPsUsage(provider common.ConfigurationProvider, compartmentID string) UsageMetric {
-	usage := UsageMetric{Limit: 2} // Límite estándar de Free Tier
+// El segundo valor indica si se alcanzó el tope de páginas
+func getPublicIPsUsage(ctx context.Context, backend ociBackend, compartmentID string, pagination paginationConfig) (UsageMetric, bool, error) {
+	usage := UsageMetric{Limit: float64(currentLimits().PublicIPs.Reserved)}
 
-	client, err := core.NewVirtualNetworkClientWithConfigurationProvider(provider)
+	client, err := backend.VirtualNetwork()
 	if err != nil {
-		return usage
-	}
-
-	request := core.ListPublicIpsRequest{
-		CompartmentId: common.String(compartmentID),
-		Scope:         core.ListPublicIpsScopeRegion,
+		return usage, false, err
 	}
 
-	response, err := client.ListPublicIps(context.Background(), request)
+	count := 0
+	truncated, err := pagination.paginate(func(page *string) (*string, error) {
+		response, err := client.ListPublicIps(ctx, core.ListPublicIpsRequest{
+			CompartmentId: common.String(compartmentID),
+			Scope:         core.ListPublicIpsScopeRegion,
+			Limit:         common.Int(pagination.PageLimit),
+			Page:          page,
+		})
+		if err != nil {
+			return nil, ociError("core", "ListPublicIps", err)
+		}
+		count += len(response.Items)
+		return response.OpcNextPage, nil
+	})
 	if err != nil {
-		return usage
+		return usage, false, err
 	}
 
-	count := len(response.Items)
 	usage.Used = float64(count)
 	usage.Percentage = int((usage.Used / usage.Limit) * 100)
 
-	return usage
+	return usage, truncated, nil
+}
+
+// stringValue devuelve el valor de un *string del SDK o "" si es nil
+// En Go, desreferenciar un puntero nil provoca un panic que tumba todo el proceso:
+// los campos opcionales (mandatory:"false") nunca se desreferencian directamente
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
 
 // getComputeUsage obtiene el uso de compute
-func getComputeUsage(provider common.ConfigurationProvider, compartmentID string) ComputeUsage {
+// Devuelve también el error para que getOCIUsage pueda informar de él con detalle
+func getComputeUsage(ctx context.Context, backend ociBackend, compartmentID string, pagination paginationConfig) (ComputeUsage, error) {
 	usage := ComputeUsage{}
+	limits := currentLimits()
 
 	// Crear cliente de Compute
-	client, err := core.NewComputeClientWithConfigurationProvider(provider)
+	client, err := backend.Compute()
 	if err != nil {
 		usage.Error = err.Error()
-		return usage
+		return usage, err
 	}
 
-	// Listar instancias en ejecución
+	// Listar instancias en ejecución (todas las páginas)
 	// En Go, los parámetros de request suelen ser structs
-	request := core.ListInstancesRequest{
-		CompartmentId:  common.String(compartmentID),
-		LifecycleState: core.InstanceLifecycleStateRunning,
-	}
-
-	response, err := client.ListInstances(context.Background(), request)
+	var instances []core.Instance
+	truncated, err := pagination.paginate(func(page *string) (*string, error) {
+		request := core.ListInstancesRequest{
+			CompartmentId:  common.String(compartmentID),
+			LifecycleState: core.InstanceLifecycleStateRunning,
+			Limit:          common.Int(pagination.PageLimit),
+			Page:           page,
+		}
+		response, err := client.ListInstances(ctx, request)
+		if err != nil {
+			return nil, ociError("core", "ListInstances", err)
+		}
+		instances = append(instances, response.Items...)
+		return response.OpcNextPage, nil
+	})
 	if err != nil {
 		usage.Error = err.Error()
-		return usage
+		return usage, err
 	}
+	usage.Truncated = truncated
 
 	// Procesar las instancias
 	var armOCPUs, armMemoryGB float64
 	var armCount, amdCount int
-
-	for _, instance := range response.Items {
-		shape := *instance.Shape
-
-		// Detectar si es ARM (Ampere) o AMD
-		if strings.Contains(shape, "A1") || strings.Contains(shape, "Ampere") {
-			if instance.ShapeConfig != nil {
-				if instance.ShapeConfig.Ocpus != nil {
-					armOCPUs += float64(*instance.ShapeConfig.Ocpus)
-				}
-				if instance.ShapeConfig.MemoryInGBs != nil {
-					armMemoryGB += float64(*instance.ShapeConfig.MemoryInGBs)
-				}
+	usage.Instances = []InstanceInfo{}
+
+	for _, instance := range instances {
+		shape := stringValue(instance.Shape)
+		info := InstanceInfo{
+			ID:                 stringValue(instance.Id),
+			Name:               stringValue(instance.DisplayName),
+			Shape:              shape,
+			Arch:               instanceArch(shape),
+			AvailabilityDomain: stringValue(instance.AvailabilityDomain),
+			CompartmentID:      compartmentID,
+		}
+		info.Billing, info.BillingReason = classifyInstance(shape)
+		if instance.ShapeConfig != nil {
+			if instance.ShapeConfig.Ocpus != nil {
+				info.OCPUs = float64(*instance.ShapeConfig.Ocpus)
+			}
+			if instance.ShapeConfig.MemoryInGBs != nil {
+				info.MemoryGB = float64(*instance.ShapeConfig.MemoryInGBs)
 			}
+			if instance.ShapeConfig.NetworkingBandwidthInGbps != nil {
+				info.NetworkBandwidthGbps = float64(*instance.ShapeConfig.NetworkingBandwidthInGbps)
+			}
+		}
+
+		// Solo las shapes Always Free cuentan contra su asignación (ver instanceArch)
+		switch info.Arch {
+		case "arm":
+			armOCPUs += info.OCPUs
+			armMemoryGB += info.MemoryGB
 			armCount++
-		} else if strings.Contains(shape, "Micro") || strings.Contains(shape, "E2.1.Micro") {
+		case "amd":
 			amdCount++
 		}
+		usage.Instances = append(usage.Instances, info)
 	}
 
 	// Calcular porcentajes
 	usage.ARM.OCPUs = UsageMetric{
 		Used:       armOCPUs,
-		Limit:      Limits.Compute.ARM.OCPUs,
-		Percentage: int((armOCPUs / Limits.Compute.ARM.OCPUs) * 100),
+		Limit:      limits.Compute.ARM.OCPUs,
+		Percentage: int((armOCPUs / limits.Compute.ARM.OCPUs) * 100),
 	}
 	usage.ARM.MemoryGB = UsageMetric{
 		Used:       armMemoryGB,
-		Limit:      Limits.Compute.ARM.MemoryGB,
-		Percentage: int((armMemoryGB / Limits.Compute.ARM.MemoryGB) * 100),
+		Limit:      limits.Compute.ARM.MemoryGB,
+		Percentage: int((armMemoryGB / limits.Compute.ARM.MemoryGB) * 100),
 	}
 	usage.ARM.Instances = armCount
 	usage.AMD.Instances = UsageMetric{
 		Used:       float64(amdCount),
-		Limit:      float64(Limits.Compute.AMD.MaxInstances),
-		Percentage: int((float64(amdCount) / float64(Limits.Compute.AMD.MaxInstances)) * 100),
+		Limit:      float64(limits.Compute.AMD.MaxInstances),
+		Percentage: int((float64(amdCount) / float64(limits.Compute.AMD.MaxInstances)) * 100),
 	}
-	usage.TotalInstances = len(response.Items)
+	usage.TotalInstances = len(instances)
 
-	return usage
+	return usage, nil
 }
 
 // getBlockStorageUsage obtiene el uso de block storage
-func getBlockStorageUsage(provider common.ConfigurationProvider, compartmentID string) StorageUsage {
+func getBlockStorageUsage(ctx context.Context, backend ociBackend, compartmentID string, pagination paginationConfig) (StorageUsage, error) {
 	usage := StorageUsage{}
+	limits := currentLimits()
 
-	client, err := core.NewBlockstorageClientWithConfigurationProvider(provider)
+	client, err := backend.Blockstorage()
 	if err != nil {
 		usage.Error = err.Error()
-		return usage
+		return usage, err
 	}
 
-	// Obtener boot volumes
-	bootRequest := core.ListBootVolumesRequest{
-		CompartmentId: common.String(compartmentID),
-	}
-	bootResponse, err := client.ListBootVolumes(context.Background(), bootRequest)
+	// Obtener boot volumes (todas las páginas)
+	var bootVolumes []core.BootVolume
+	bootTruncated, err := pagination.paginate(func(page *string) (*string, error) {
+		bootRequest := core.ListBootVolumesRequest{
+			CompartmentId: common.String(compartmentID),
+			Limit:         common.Int(pagination.PageLimit),
+			Page:          page,
+		}
+		bootResponse, err := client.ListBootVolumes(ctx, bootRequest)
+		if err != nil {
+			return nil, ociError("core", "ListBootVolumes", err)
+		}
+		bootVolumes = append(bootVolumes, bootResponse.Items...)
+		return bootResponse.OpcNextPage, nil
+	})
 	if err != nil {
 		usage.Error = err.Error()
-		return usage
+		return usage, err
 	}
 
 	var bootVolumeGB int64
-	for _, vol := range bootResponse.Items {
+	usage.Volumes = []VolumeInfo{}
+	for _, vol := range bootVolumes {
+		var sizeGB int64
 		if vol.SizeInGBs != nil {
-			bootVolumeGB += *vol.SizeInGBs
+			sizeGB = *vol.SizeInGBs
+			bootVolumeGB += sizeGB
 		}
+		billing, reason, vpus := classifyVolume(vol.VpusPerGB, vol.AutoTunedVpusPerGB)
+		usage.Volumes = append(usage.Volumes, VolumeInfo{
+			ID:                 stringValue(vol.Id),
+			Name:               stringValue(vol.DisplayName),
+			Type:               "boot",
+			SizeGB:             int(sizeGB),
+			AvailabilityDomain: stringValue(vol.AvailabilityDomain),
+			CompartmentID:      compartmentID,
+			VpusPerGB:          vpus,
+			Billing:            billing,
+			BillingReason:      reason,
+		})
 	}
-	usage.BootVolumes.Count = len(bootResponse.Items)
+	usage.BootVolumes.Count = len(bootVolumes)
 	usage.BootVolumes.SizeGB = int(bootVolumeGB)
 
-	// Obtener block volumes
-	blockRequest := core.ListVolumesRequest{
-		CompartmentId: common.String(compartmentID),
-	}
-	blockResponse, err := client.ListVolumes(context.Background(), blockRequest)
+	// Obtener block volumes (todas las páginas)
+	var blockVolumes []core.Volume
+	blockTruncated, err := pagination.paginate(func(page *string) (*string, error) {
+		blockRequest := core.ListVolumesRequest{
+			CompartmentId: common.String(compartmentID),
+			Limit:         common.Int(pagination.PageLimit),
+			Page:          page,
+		}
+		blockResponse, err := client.ListVolumes(ctx, blockRequest)
+		if err != nil {
+			return nil, ociError("core", "ListVolumes", err)
+		}
+		blockVolumes = append(blockVolumes, blockResponse.Items...)
+		return blockResponse.OpcNextPage, nil
+	})
 	if err != nil {
 		usage.Error = err.Error()
-		return usage
+		return usage, err
 	}
+	usage.Truncated = bootTruncated || blockTruncated
 
 	var blockVolumeGB int64
-	for _, vol := range blockResponse.Items {
+	for _, vol := range blockVolumes {
+		var sizeGB int64
 		if vol.SizeInGBs != nil {
-			blockVolumeGB += *vol.SizeInGBs
+			sizeGB = *vol.SizeInGBs
+			blockVolumeGB += sizeGB
 		}
+		billing, reason, vpus := classifyVolume(vol.VpusPerGB, vol.AutoTunedVpusPerGB)
+		usage.Volumes = append(usage.Volumes, VolumeInfo{
+			ID:                 stringValue(vol.Id),
+			Name:               stringValue(vol.DisplayName),
+			Type:               "block",
+			SizeGB:             int(sizeGB),
+			AvailabilityDomain: stringValue(vol.AvailabilityDomain),
+			CompartmentID:      compartmentID,
+			VpusPerGB:          vpus,
+			Billing:            billing,
+			BillingReason:      reason,
+		})
 	}
-	usage.BlockVolumes.Count = len(blockResponse.Items)
+	usage.BlockVolumes.Count = len(blockVolumes)
 	usage.BlockVolumes.SizeGB = int(blockVolumeGB)
 
 	// Total
 	totalGB := int(bootVolumeGB + blockVolumeGB)
 	usage.Total = UsageMetric{
 		Used:       float64(totalGB),
-		Limit:      float64(Limits.BlockStorage.TotalGB),
-		Percentage: int((float64(totalGB) / float64(Limits.BlockStorage.TotalGB)) * 100),
+		Limit:      float64(limits.BlockStorage.TotalGB),
+		Percentage: int((float64(totalGB) / float64(limits.BlockStorage.TotalGB)) * 100),
 	}
 
-	return usage
+	return usage, nil
 }
 
-// getObjectStorageUsage obtiene el uso de object storage
-func getObjectStorageUsage(provider common.ConfigurationProvider, compartmentID string) ObjectStorageUsage {
+// getObjectStorageUsage obtiene el tamaño de los buckets de object storage
+// Las peticiones del mes las cuenta otro colector (objectStorageRequests) con Monitoring
+func getObjectStorageUsage(ctx context.Context, backend ociBackend, compartmentID string, pagination paginationConfig) (ObjectStorageUsage, error) {
 	usage := ObjectStorageUsage{}
+	limits := currentLimits()
 
-	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(provider)
+	client, err := backend.ObjectStorage()
 	if err != nil {
 		usage.Error = err.Error()
-		return usage
+		return usage, err
 	}
 
 	// Obtener namespace (requerido para object storage)
 	nsRequest := objectstorage.GetNamespaceRequest{}
-	nsResponse, err := client.GetNamespace(context.Background(), nsRequest)
+	nsResponse, err := client.GetNamespace(ctx, nsRequest)
 	if err != nil {
+		err = ociError("objectstorage", "GetNamespace", err)
 		usage.Error = err.Error()
-		return usage
+		return usage, err
 	}
-	namespace := *nsResponse.Value
-
-	// Listar buckets
-	bucketsRequest := objectstorage.ListBucketsRequest{
-		NamespaceName: common.String(namespace),
-		CompartmentId: common.String(compartmentID),
+	// Una respuesta sin cuerpo no trae namespace: sin él no se pueden listar los buckets
+	namespace := stringValue(nsResponse.Value)
+	if namespace == "" {
+		err = ociError("objectstorage", "GetNamespace", errors.New("empty namespace in response"))
+		usage.Error = err.Error()
+		return usage, err
 	}
-	bucketsResponse, err := client.ListBuckets(context.Background(), bucketsRequest)
+
+	// Listar buckets (todas las páginas)
+	var buckets []objectstorage.BucketSummary
+	truncated, err := pagination.paginate(func(page *string) (*string, error) {
+		bucketsRequest := objectstorage.ListBucketsRequest{
+			NamespaceName: common.String(namespace),
+			CompartmentId: common.String(compartmentID),
+			Limit:         common.Int(pagination.PageLimit),
+			Page:          page,
+		}
+		bucketsResponse, err := client.ListBuckets(ctx, bucketsRequest)
+		if err != nil {
+			return nil, ociError("objectstorage", "ListBuckets", err)
+		}
+		buckets = append(buckets, bucketsResponse.Items...)
+		return bucketsResponse.OpcNextPage, nil
+	})
 	if err != nil {
 		usage.Error = err.Error()
-		return usage
+		return usage, err
 	}
+	usage.Truncated = truncated
 
 	var totalBytes int64
+	var bucketErr error // primer fallo de GetBucket: el total queda por debajo del real
 	usage.Buckets = []BucketInfo{}
 
-	for _, bucket := range bucketsResponse.Items {
+	for _, bucket := range buckets {
 		// Obtener detalles del bucket (incluyendo tamaño aproximado)
 		bucketRequest := objectstorage.GetBucketRequest{
 			NamespaceName: common.String(namespace),
 			BucketName:    bucket.Name,
 			Fields:        []objectstorage.GetBucketFieldsEnum{objectstorage.GetBucketFieldsApproximatesize},
 		}
-		bucketResponse, err := client.GetBucket(context.Background(), bucketRequest)
+		bucketResponse, err := client.GetBucket(ctx, bucketRequest)
 		if err != nil {
+			if bucketErr == nil {
+				bucketErr = ociError("objectstorage", "GetBucket", err)
+			}
 			usage.Buckets = append(usage.Buckets, BucketInfo{
-				Name:   *bucket.Name,
-				SizeGB: -1, // Indicar error
+				Name:          stringValue(bucket.Name),
+				SizeGB:        -1, // Indicar error
+				CompartmentID: compartmentID,
 			})
 			continue
 		}
@@ -326,56 +578,210 @@ func getObjectStorageUsage(provider common.ConfigurationProvider, compartmentID
 		}
 
 		usage.Buckets = append(usage.Buckets, BucketInfo{
-			Name:   *bucket.Name,
-			SizeGB: sizeGB,
+			Name:          stringValue(bucket.Name),
+			SizeGB:        sizeGB,
+			CompartmentID: compartmentID,
 		})
 	}
 
 	totalGB := float64(totalBytes) / (1024 * 1024 * 1024)
 	usage.Total = UsageMetric{
 		Used:       totalGB,
-		Limit:      float64(Limits.ObjectStorage.TotalGB),
-		Percentage: int((totalGB / float64(Limits.ObjectStorage.TotalGB)) * 100),
+		Limit:      float64(limits.ObjectStorage.TotalGB),
+		Percentage: int((totalGB / float64(limits.ObjectStorage.TotalGB)) * 100),
 	}
 
-	return usage
+	if bucketErr != nil {
+		usage.Error = bucketErr.Error()
+	}
+	return usage, bucketErr
+}
+
+// applyObjectStorageRequests añade al uso de object storage las peticiones del mes
+// (total, proyección a fin de mes y reparto por bucket)
+// now es el instante hasta el que se cuentan las peticiones
+func applyObjectStorageRequests(usage *ObjectStorageUsage, requests map[string]float64, now time.Time) {
+	limits := currentLimits()
+	var totalRequests float64
+	for _, count := range requests {
+		totalRequests += count
+	}
+	for i := range usage.Buckets {
+		usage.Buckets[i].Requests = requests[usage.Buckets[i].Name]
+	}
+	projectedRequests := projectToMonthEnd(totalRequests, now)
+	usage.Requests = UsageMetric{
+		Used:       totalRequests,
+		Limit:      float64(limits.ObjectStorage.RequestsPerMonth),
+		Percentage: int((totalRequests / float64(limits.ObjectStorage.RequestsPerMonth)) * 100),
+	}
+	usage.ProjectedRequests = UsageMetric{
+		Used:       projectedRequests,
+		Limit:      float64(limits.ObjectStorage.RequestsPerMonth),
+		Percentage: int((projectedRequests / float64(limits.ObjectStorage.RequestsPerMonth)) * 100),
+	}
+}
+
+// getObjectStorageRequests cuenta las peticiones del mes por bucket (métrica AllRequests)
+// Las series sin nombre de bucket se agrupan con la clave vacía: cuentan para el total
+func getObjectStorageRequests(ctx context.Context, backend ociBackend, compartmentID string, now time.Time) (map[string]float64, error) {
+	client, err := backend.Monitoring()
+	if err != nil {
+		return nil, err
+	}
+	series, err := monthToDateSeries(ctx, client, compartmentID, "oci_objectstorage", "AllRequests[1d].sum()", now)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := map[string]float64{}
+	for _, s := range series {
+		requests[s.Dimensions["resourceDisplayName"]] += sumDatapoints(s)
+	}
+	return requests, nil
 }
 
 // getLoadBalancerUsage obtiene el uso de load balancers
-func getLoadBalancerUsage(provider common.ConfigurationProvider, compartmentID string) LoadBalancerUsage {
+func getLoadBalancerUsage(ctx context.Context, backend ociBackend, compartmentID string, pagination paginationConfig) (LoadBalancerUsage, error) {
 	usage := LoadBalancerUsage{}
+	limits := currentLimits()
 
-	client, err := loadbalancer.NewLoadBalancerClientWithConfigurationProvider(provider)
+	client, err := backend.LoadBalancer()
 	if err != nil {
 		usage.Error = err.Error()
-		return usage
-	}
-
-	request := loadbalancer.ListLoadBalancersRequest{
-		CompartmentId: common.String(compartmentID),
+		return usage, err
 	}
 
-	response, err := client.ListLoadBalancers(context.Background(), request)
+	var loadBalancers []loadbalancer.LoadBalancer
+	truncated, err := pagination.paginate(func(page *string) (*string, error) {
+		request := loadbalancer.ListLoadBalancersRequest{
+			CompartmentId: common.String(compartmentID),
+			Limit:         common.Int64(int64(pagination.PageLimit)),
+			Page:          page,
+		}
+		response, err := client.ListLoadBalancers(ctx, request)
+		if err != nil {
+			return nil, ociError("loadbalancer", "ListLoadBalancers", err)
+		}
+		loadBalancers = append(loadBalancers, response.Items...)
+		return response.OpcNextPage, nil
+	})
 	if err != nil {
 		usage.Error = err.Error()
-		return usage
+		return usage, err
 	}
+	usage.Truncated = truncated
 
-	count := len(response.Items)
+	count := len(loadBalancers)
 	usage.Count = UsageMetric{
 		Used:       float64(count),
-		Limit:      float64(Limits.LoadBalancer.Instances),
-		Percentage: int((float64(count) / float64(Limits.LoadBalancer.Instances)) * 100),
+		Limit:      float64(limits.LoadBalancer.Instances),
+		Percentage: int((float64(count) / float64(limits.LoadBalancer.Instances)) * 100),
 	}
 
 	usage.LoadBalancers = []LoadBalancerInfo{}
-	for _, lb := range response.Items {
-		usage.LoadBalancers = append(usage.LoadBalancers, LoadBalancerInfo{
-			Name:  *lb.DisplayName,
-			Shape: *lb.ShapeName,
-			State: string(lb.LifecycleState),
-		})
+	for _, lb := range loadBalancers {
+		info := LoadBalancerInfo{
+			ID:            stringValue(lb.Id),
+			Name:          stringValue(lb.DisplayName),
+			Shape:         stringValue(lb.ShapeName),
+			State:         string(lb.LifecycleState),
+			CompartmentID: compartmentID,
+		}
+		var maxMbps *int
+		if lb.ShapeDetails != nil {
+			maxMbps = lb.ShapeDetails.MaximumBandwidthInMbps
+		}
+		if maxMbps != nil {
+			info.MaxBandwidthMbps = *maxMbps
+		}
+		info.Billing, info.BillingReason = classifyLoadBalancer(info.Shape, maxMbps, limits.LoadBalancer.BandwidthMbps)
+		usage.LoadBalancers = append(usage.LoadBalancers, info)
 	}
 
-	return usage
+	return usage, nil
+}
+
+// getDatabaseUsage obtiene el uso de Autonomous Database
+// Solo las bases de datos con IsFreeTier cuentan contra la asignación Always Free;
+// el resto se factura y se cuenta en Paid
+func getDatabaseUsage(ctx context.Context, backend ociBackend, compartmentID string, pagination paginationConfig) (DatabaseUsage, error) {
+	usage := DatabaseUsage{}
+	limits := currentLimits()
+
+	client, err := backend.Database()
+	if err != nil {
+		usage.Error = err.Error()
+		return usage, err
+	}
+
+	var databases []database.AutonomousDatabaseSummary
+	truncated, err := pagination.paginate(func(page *string) (*string, error) {
+		request := database.ListAutonomousDatabasesRequest{
+			CompartmentId: common.String(compartmentID),
+			Limit:         common.Int(pagination.PageLimit),
+			Page:          page,
+		}
+		response, err := client.ListAutonomousDatabases(ctx, request)
+		if err != nil {
+			return nil, ociError("database", "ListAutonomousDatabases", err)
+		}
+		databases = append(databases, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	if err != nil {
+		usage.Error = err.Error()
+		return usage, err
+	}
+	usage.Truncated = truncated
+
+	var freeCount, freeStorageGB int
+	usage.Databases = []DatabaseInfo{}
+	for _, db := range databases {
+		// Las bases de datos terminadas siguen apareciendo un tiempo en el listado
+		if db.LifecycleState == database.AutonomousDatabaseSummaryLifecycleStateTerminated ||
+			db.LifecycleState == database.AutonomousDatabaseSummaryLifecycleStateTerminating {
+			continue
+		}
+
+		info := DatabaseInfo{
+			ID:            stringValue(db.Id),
+			Name:          stringValue(db.DbName),
+			IsFreeTier:    db.IsFreeTier != nil && *db.IsFreeTier,
+			State:         string(db.LifecycleState),
+			CompartmentID: compartmentID,
+		}
+		if db.DisplayName != nil {
+			info.Name = *db.DisplayName
+		}
+		// DataStorageSizeInGBs es más preciso: las Always Free tienen 20 GB, menos de 1 TB
+		if db.DataStorageSizeInGBs != nil {
+			info.StorageGB = *db.DataStorageSizeInGBs
+		} else if db.DataStorageSizeInTBs != nil {
+			info.StorageGB = *db.DataStorageSizeInTBs * 1024
+		}
+
+		if info.IsFreeTier {
+			freeCount++
+			freeStorageGB += info.StorageGB
+		} else {
+			usage.Paid++
+		}
+		usage.Databases = append(usage.Databases, info)
+	}
+
+	// Oracle da totalStorageGB a cada base de datos Always Free
+	storageLimit := limits.Database.TotalStorageGB * limits.Database.AutonomousDBs
+	usage.AutonomousDBs = UsageMetric{
+		Used:       float64(freeCount),
+		Limit:      float64(limits.Database.AutonomousDBs),
+		Percentage: int((float64(freeCount) / float64(limits.Database.AutonomousDBs)) * 100),
+	}
+	usage.StorageGB = UsageMetric{
+		Used:       float64(freeStorageGB),
+		Limit:      float64(storageLimit),
+		Percentage: int((float64(freeStorageGB) / float64(storageLimit)) * 100),
+	}
+
+	return usage, nil
 }
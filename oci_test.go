@@ -0,0 +1,397 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// testCompartment es el compartimento de los fixtures de testdata
+const testCompartment = "ocid1.compartment.oc1..test"
+
+// testPagination pide todo en una sola página
+var testPagination = paginationConfig{PageLimit: 100, MaxPages: 50}
+
+func TestGetComputeUsageShapes(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		wantARM     int
+		wantOCPUs   float64
+		wantMemory  float64
+		wantAMD     float64
+		wantTotal   int
+		wantArchOf  map[string]string
+		wantPercent int
+	}{
+		{
+			name:        "free tier: ARM, Micro e instancias paradas",
+			fixture:     "free_tier.json",
+			wantARM:     2,
+			wantOCPUs:   2,
+			wantMemory:  12,
+			wantAMD:     1,
+			wantTotal:   3,
+			wantArchOf:  map[string]string{"arm-1": "arm", "micro-1": "amd"},
+			wantPercent: 50,
+		},
+		{
+			name:        "por encima del límite y shape de pago",
+			fixture:     "over_limits.json",
+			wantARM:     2,
+			wantOCPUs:   5,
+			wantMemory:  30,
+			wantAMD:     0,
+			wantTotal:   3,
+			wantArchOf:  map[string]string{"arm-big": "arm", "amd-flex": "other"},
+			wantPercent: 125,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newScenarioBackend(loadFixture(t, tt.fixture))
+			usage, err := getComputeUsage(context.Background(), backend, testCompartment, testPagination)
+			if err != nil {
+				t.Fatalf("getComputeUsage() error = %v", err)
+			}
+			if usage.ARM.Instances != tt.wantARM {
+				t.Errorf("ARM.Instances = %d; want %d", usage.ARM.Instances, tt.wantARM)
+			}
+			if usage.ARM.OCPUs.Used != tt.wantOCPUs || usage.ARM.OCPUs.Percentage != tt.wantPercent {
+				t.Errorf("ARM.OCPUs = %+v; want used %v at %d%%", usage.ARM.OCPUs, tt.wantOCPUs, tt.wantPercent)
+			}
+			if usage.ARM.MemoryGB.Used != tt.wantMemory {
+				t.Errorf("ARM.MemoryGB.Used = %v; want %v", usage.ARM.MemoryGB.Used, tt.wantMemory)
+			}
+			if usage.AMD.Instances.Used != tt.wantAMD {
+				t.Errorf("AMD.Instances.Used = %v; want %v", usage.AMD.Instances.Used, tt.wantAMD)
+			}
+			if usage.TotalInstances != tt.wantTotal {
+				t.Errorf("TotalInstances = %d; want %d", usage.TotalInstances, tt.wantTotal)
+			}
+			for _, instance := range usage.Instances {
+				if want, ok := tt.wantArchOf[instance.Name]; ok && instance.Arch != want {
+					t.Errorf("%s arch = %q; want %q", instance.Name, instance.Arch, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetBlockStorageUsageTotals(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		wantBoot    int
+		wantBlock   int
+		wantTotal   float64
+		wantPercent int
+		wantVolumes int
+	}{
+		{"free tier", "free_tier.json", 94, 10, 104, 52, 3},
+		{"por encima del límite", "over_limits.json", 200, 50, 250, 125, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newScenarioBackend(loadFixture(t, tt.fixture))
+			usage, err := getBlockStorageUsage(context.Background(), backend, testCompartment, testPagination)
+			if err != nil {
+				t.Fatalf("getBlockStorageUsage() error = %v", err)
+			}
+			if usage.BootVolumes.SizeGB != tt.wantBoot || usage.BlockVolumes.SizeGB != tt.wantBlock {
+				t.Errorf("boot/block = %d/%d GB; want %d/%d", usage.BootVolumes.SizeGB, usage.BlockVolumes.SizeGB, tt.wantBoot, tt.wantBlock)
+			}
+			if usage.Total.Used != tt.wantTotal || usage.Total.Percentage != tt.wantPercent {
+				t.Errorf("Total = %+v; want %v at %d%%", usage.Total, tt.wantTotal, tt.wantPercent)
+			}
+			if len(usage.Volumes) != tt.wantVolumes {
+				t.Errorf("len(Volumes) = %d; want %d", len(usage.Volumes), tt.wantVolumes)
+			}
+		})
+	}
+}
+
+// TestCollectorsWithoutOptionalFields verifica que un recurso sin los campos opcionales
+// del SDK (como displayName) no provoca un panic en el colector
+func TestCollectorsWithoutOptionalFields(t *testing.T) {
+	scenario := &ociScenario{
+		Instances: []core.Instance{{
+			Id:                 common.String("ocid1.instance.oc1..noname"),
+			CompartmentId:      common.String(testCompartment),
+			AvailabilityDomain: common.String("AD-1"),
+			Shape:              common.String("VM.Standard.A1.Flex"),
+			LifecycleState:     core.InstanceLifecycleStateRunning,
+		}},
+		BootVolumes: []core.BootVolume{{
+			Id:                 common.String("ocid1.bootvolume.oc1..noname"),
+			CompartmentId:      common.String(testCompartment),
+			AvailabilityDomain: common.String("AD-1"),
+			SizeInGBs:          common.Int64(50),
+		}},
+	}
+	backend := newScenarioBackend(scenario)
+
+	compute, err := getComputeUsage(context.Background(), backend, testCompartment, testPagination)
+	if err != nil || len(compute.Instances) != 1 || compute.Instances[0].Name != "" {
+		t.Errorf("getComputeUsage() = %+v, %v", compute.Instances, err)
+	}
+	storage, err := getBlockStorageUsage(context.Background(), backend, testCompartment, testPagination)
+	if err != nil || len(storage.Volumes) != 1 || storage.Total.Used != 50 {
+		t.Errorf("getBlockStorageUsage() = %+v, %v", storage, err)
+	}
+	// El escenario no tiene namespace: GetNamespace responde sin valor
+	var ociErr *ociCallError
+	if _, err := getObjectStorageUsage(context.Background(), backend, testCompartment, testPagination); !errors.As(err, &ociErr) || ociErr.Operation != "GetNamespace" {
+		t.Errorf("getObjectStorageUsage() error = %v; want a GetNamespace error", err)
+	}
+}
+
+func TestGetObjectStorageUsageBucketSizing(t *testing.T) {
+	tests := []struct {
+		name      string
+		errors    map[string]scenarioError
+		wantSizes map[string]float64
+		wantTotal float64
+		wantErr   bool
+	}{
+		{
+			name:      "tamaño aproximado de cada bucket",
+			wantSizes: map[string]float64{"backups": 2, "logs": 1},
+			wantTotal: 3,
+		},
+		{
+			name:      "fallo de GetBucket marca el bucket con -1",
+			errors:    map[string]scenarioError{"GetBucket:logs": {Status: 403, Code: "NotAuthorizedOrNotFound", Message: "denied"}},
+			wantSizes: map[string]float64{"backups": 2, "logs": -1},
+			wantTotal: 2,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := loadFixture(t, "free_tier.json")
+			fixture.Errors = tt.errors
+			usage, err := getObjectStorageUsage(context.Background(), newScenarioBackend(fixture), testCompartment, testPagination)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getObjectStorageUsage() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			for _, bucket := range usage.Buckets {
+				if want := tt.wantSizes[bucket.Name]; bucket.SizeGB != want {
+					t.Errorf("%s SizeGB = %v; want %v", bucket.Name, bucket.SizeGB, want)
+				}
+			}
+			if len(usage.Buckets) != len(tt.wantSizes) {
+				t.Errorf("len(Buckets) = %d; want %d", len(usage.Buckets), len(tt.wantSizes))
+			}
+			if usage.Total.Used != tt.wantTotal {
+				t.Errorf("Total.Used = %v; want %v", usage.Total.Used, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestGetDatabaseUsage(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		wantFree    float64
+		wantStorage float64
+		wantPaid    int
+		wantListed  []string
+	}{
+		{"Always Free, ignorando las terminadas", "free_tier.json", 1, 20, 0, []string{"free-db"}},
+		{"Always Free parada y una de pago", "over_limits.json", 1, 20, 1, []string{"FREEDB", "paid-db"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newScenarioBackend(loadFixture(t, tt.fixture))
+			usage, err := getDatabaseUsage(context.Background(), backend, testCompartment, testPagination)
+			if err != nil {
+				t.Fatalf("getDatabaseUsage() error = %v", err)
+			}
+			if usage.AutonomousDBs.Used != tt.wantFree || usage.AutonomousDBs.Limit != 2 {
+				t.Errorf("AutonomousDBs = %+v; want %v of 2", usage.AutonomousDBs, tt.wantFree)
+			}
+			if usage.StorageGB.Used != tt.wantStorage || usage.StorageGB.Limit != 40 {
+				t.Errorf("StorageGB = %+v; want %v of 40", usage.StorageGB, tt.wantStorage)
+			}
+			if usage.Paid != tt.wantPaid {
+				t.Errorf("Paid = %d; want %d", usage.Paid, tt.wantPaid)
+			}
+			if len(usage.Databases) != len(tt.wantListed) {
+				t.Fatalf("Databases = %+v; want %v", usage.Databases, tt.wantListed)
+			}
+			for i, name := range tt.wantListed {
+				if usage.Databases[i].Name != name {
+					t.Errorf("Databases[%d].Name = %q; want %q", i, usage.Databases[i].Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestGetObjectStorageUsageRequests(t *testing.T) {
+	// 20.000 peticiones en los 5 primeros días de octubre: 124.000 a fin de mes
+	now := time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC)
+	backend := newScenarioBackend(loadFixture(t, "over_limits.json"))
+
+	usage, err := getObjectStorageUsage(context.Background(), backend, testCompartment, testPagination)
+	if err != nil {
+		t.Fatalf("getObjectStorageUsage() error = %v", err)
+	}
+	requests, err := getObjectStorageRequests(context.Background(), backend, testCompartment, now)
+	if err != nil {
+		t.Fatalf("getObjectStorageRequests() error = %v", err)
+	}
+	applyObjectStorageRequests(&usage, requests, now)
+
+	if usage.Requests.Used != 20000 || usage.Requests.Limit != 50000 {
+		t.Errorf("Requests = %+v; want 20000 of 50000", usage.Requests)
+	}
+	if usage.ProjectedRequests.Used != 124000 {
+		t.Errorf("ProjectedRequests.Used = %v; want 124000", usage.ProjectedRequests.Used)
+	}
+	if len(usage.Buckets) != 1 || usage.Buckets[0].Requests != 15000 {
+		t.Errorf("Buckets = %+v; want media with 15000 requests", usage.Buckets)
+	}
+}
+
+// TestCollectCompartmentRequestsFailure verifica que sin permiso para leer métricas solo
+// se pierden las peticiones: el tamaño de los buckets sigue siendo fiable
+func TestCollectCompartmentRequestsFailure(t *testing.T) {
+	fixture := loadFixture(t, "over_limits.json")
+	fixture.Errors = map[string]scenarioError{
+		"SummarizeMetricsData:oci_objectstorage/AllRequests": {Status: 404, Code: "NotAuthorizedOrNotFound", Message: "denied"},
+	}
+	usage := collectCompartment(context.Background(), newScenarioBackend(fixture), testCompartment, testPagination, currentTimeouts())
+
+	failed := failedCollectors(usage)
+	if !failed["objectStorageRequests"] || failed["objectStorage"] {
+		t.Errorf("failed collectors = %v; want only objectStorageRequests", failed)
+	}
+	metrics := reliableUsageMetrics(usage)
+	if _, ok := metrics["objectStorage.total"]; !ok {
+		t.Error("objectStorage.total dropped")
+	}
+	if _, ok := metrics["objectStorage.requests"]; ok {
+		t.Error("objectStorage.requests kept without Monitoring data")
+	}
+}
+
+func TestCollectCompartmentPagination(t *testing.T) {
+	tests := []struct {
+		name          string
+		pagination    paginationConfig
+		wantInstances int
+		wantCalls     int
+		wantTruncated []string
+	}{
+		{"todas las páginas", paginationConfig{PageLimit: 1, MaxPages: 10}, 3, 3, nil},
+		{"tope de páginas alcanzado", paginationConfig{PageLimit: 1, MaxPages: 2}, 2, 2, []string{"compute"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newScenarioBackend(loadFixture(t, "free_tier.json"))
+			usage := collectCompartment(context.Background(), backend, testCompartment, tt.pagination, currentTimeouts())
+			if usage.Compute.TotalInstances != tt.wantInstances {
+				t.Errorf("TotalInstances = %d; want %d", usage.Compute.TotalInstances, tt.wantInstances)
+			}
+			if got := backend.callCount("ListInstances"); got != tt.wantCalls {
+				t.Errorf("ListInstances calls = %d; want %d", got, tt.wantCalls)
+			}
+			if len(usage.Truncated) != len(tt.wantTruncated) {
+				t.Fatalf("Truncated = %v; want %v", usage.Truncated, tt.wantTruncated)
+			}
+			for i := range tt.wantTruncated {
+				if usage.Truncated[i] != tt.wantTruncated[i] {
+					t.Errorf("Truncated = %v; want %v", usage.Truncated, tt.wantTruncated)
+				}
+			}
+		})
+	}
+}
+
+// TestGetOCIUsageWithFakeBackend recorre getOCIUsage completo sobre los fixtures
+// y comprueba el estado que calcula el evaluador
+func TestGetOCIUsageWithFakeBackend(t *testing.T) {
+	tests := []struct {
+		name       string
+		fixture    string
+		errors     map[string]scenarioError
+		wantStatus string
+		wantErrors []string // colectores con error
+	}{
+		{name: "dentro del free tier", fixture: "free_tier.json", wantStatus: SeverityOK},
+		{name: "por encima de los límites", fixture: "over_limits.json", wantStatus: SeverityCritical},
+		{
+			name:       "credenciales rechazadas en compute",
+			fixture:    "free_tier.json",
+			errors:     map[string]scenarioError{"ListInstances": {Status: 401, Code: "NotAuthenticated", Message: "not authenticated", OpcRequestID: "req-1"}},
+			wantStatus: SeverityDegraded,
+			wantErrors: []string{"compute"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearOCIEnv(t)
+			keyPath := filepath.Join(t.TempDir(), "key.pem")
+			if err := os.WriteFile(keyPath, []byte("unused"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			setTestOCIEnv(t)
+			t.Setenv("OCI_PRIVATE_KEY_PATH", keyPath)
+			t.Setenv("OCI_COMPARTMENT_ID", testCompartment)
+
+			fixture := loadFixture(t, tt.fixture)
+			fixture.Errors = tt.errors
+			useFakeBackend(t, newScenarioBackend(fixture))
+
+			usage, err := getOCIUsage(context.Background())
+			if err != nil {
+				t.Fatalf("getOCIUsage() error = %v", err)
+			}
+			if eval := evaluateUsage(usage); eval.Status != tt.wantStatus {
+				t.Errorf("status = %s; want %s (%+v)", eval.Status, tt.wantStatus, eval.Findings)
+			}
+
+			if len(usage.Errors) != len(tt.wantErrors) {
+				t.Fatalf("Errors = %+v; want collectors %v", usage.Errors, tt.wantErrors)
+			}
+			for i, e := range usage.Errors {
+				if e.Collector != tt.wantErrors[i] || e.HTTPStatus != 401 || e.OpcRequestID != "req-1" {
+					t.Errorf("Errors[%d] = %+v", i, e)
+				}
+			}
+		})
+	}
+}
+
+// TestGetOCIUsageCancelled verifica que una petición cancelada no devuelve datos a medias
+func TestGetOCIUsageCancelled(t *testing.T) {
+	clearOCIEnv(t)
+	setTestOCIEnv(t)
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyPath, []byte("unused"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OCI_PRIVATE_KEY_PATH", keyPath)
+	useFakeBackend(t, newScenarioBackend(loadFixture(t, "free_tier.json")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := getOCIUsage(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("getOCIUsage() error = %v; want context.Canceled", err)
+	}
+}
@@ -0,0 +1,726 @@
+// Package main - Este archivo contiene un doble de OCI para pruebas: un escenario JSON
+// con recursos y errores, servido en memoria (tests) o por HTTP ("watcher stub")
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/database"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+	"github.com/oracle/oci-go-sdk/v65/limits"
+	"github.com/oracle/oci-go-sdk/v65/loadbalancer"
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+)
+
+// ociScenario describe una cuenta de OCI falsa (ver testdata/*.json)
+// Los recursos usan el mismo formato JSON que la API de OCI y el SDK
+type ociScenario struct {
+	Instances     []core.Instance             `json:"instances"`
+	BootVolumes   []core.BootVolume           `json:"bootVolumes"`
+	Volumes       []core.Volume               `json:"volumes"`
+	PublicIps     []core.PublicIp             `json:"publicIps"`
+	Namespace     string                      `json:"namespace"`
+	Buckets       []objectstorage.Bucket      `json:"buckets"`
+	LoadBalancers []loadbalancer.LoadBalancer `json:"loadBalancers"`
+	// AutonomousDatabases usa el formato de ListAutonomousDatabases
+	AutonomousDatabases []database.AutonomousDatabaseSummary `json:"autonomousDatabases"`
+	// Metrics son las series de Monitoring por "<namespace>/<métrica>", p. ej. "oci_vnic/VnicToNetworkBytes"
+	// Se devuelven tal cual, sin recortar por fechas ni reagregar
+	Metrics map[string][]monitoring.MetricData `json:"metrics"`
+	// AvailabilityDomains y FaultDomains son los destinos que prueba el lanzador
+	AvailabilityDomains []identity.AvailabilityDomain `json:"availabilityDomains"`
+	FaultDomains        []identity.FaultDomain        `json:"faultDomains"`
+	// Capacity es el estado que devuelve CreateComputeCapacityReport por AD
+	// ("AVAILABLE", "OUT_OF_HOST_CAPACITY"...); un AD que no aparece no tiene capacidad
+	Capacity map[string]string `json:"capacity"`
+	// Compartments es la lista plana de compartimentos de la tenancy (OCI_COMPARTMENT_SCAN=recursive)
+	Compartments []identity.Compartment `json:"compartments"`
+	// RegionSubscriptions son las regiones suscritas (OCI_REGION_SCAN=all)
+	RegionSubscriptions []identity.RegionSubscription `json:"regionSubscriptions"`
+	// Limits son los valores de cada límite de servicio por "<servicio>/<límite>",
+	// p. ej. "compute/standard-a1-core-count", con una entrada por ámbito
+	Limits map[string][]scenarioLimit `json:"limits"`
+	// Errors fuerza un error por operación ("ListInstances"), por bucket ("GetBucket:<nombre>")
+	// o por destino de LaunchInstance ("LaunchInstance:<AD>/<FD>", p. ej. sin capacidad)
+	Errors map[string]scenarioError `json:"errors"`
+}
+
+// scenarioError es un error de servicio de OCI descrito en el escenario
+// Cumple common.ServiceError, así que el watcher lo trata igual que uno real
+type scenarioError struct {
+	Status       int    `json:"status"`
+	Code         string `json:"code"`
+	Message      string `json:"message"`
+	OpcRequestID string `json:"opcRequestId"`
+}
+
+// scenarioLimit es el valor de un límite en un ámbito (lo que devuelve ListLimitValues)
+// junto con su uso (lo que devuelve GetResourceAvailability para ese ámbito)
+type scenarioLimit struct {
+	limits.LimitValueSummary
+	Used      *int64 `json:"used"`
+	Available *int64 `json:"available"`
+}
+
+func (e scenarioError) Error() string           { return e.Message }
+func (e scenarioError) GetHTTPStatusCode() int  { return e.Status }
+func (e scenarioError) GetMessage() string      { return e.Message }
+func (e scenarioError) GetCode() string         { return e.Code }
+func (e scenarioError) GetOpcRequestID() string { return e.OpcRequestID }
+
+// loadScenario lee un escenario desde un fichero JSON
+func loadScenario(path string) (*ociScenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var scenario ociScenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return &scenario, nil
+}
+
+// scenarioBackend implementa ociBackend (y todas las interfaces de clientes) sobre un escenario
+// Filtra por compartimento y pagina igual que OCI: el token de página es el índice del primer elemento
+// El doble HTTP atiende peticiones en paralelo y LaunchInstance modifica el escenario:
+// todas las lecturas del escenario se hacen con mu tomado
+type scenarioBackend struct {
+	scenario *ociScenario
+	mu       sync.Mutex
+	calls    map[string]int           // llamadas por operación; los colectores corren en paralelo
+	launched map[string]core.Instance // instancias creadas por opc-retry-token
+}
+
+func newScenarioBackend(scenario *ociScenario) *scenarioBackend {
+	return &scenarioBackend{scenario: scenario, calls: map[string]int{}, launched: map[string]core.Instance{}}
+}
+
+func (b *scenarioBackend) Compute() (computeAPI, error)               { return b, nil }
+func (b *scenarioBackend) Blockstorage() (blockstorageAPI, error)     { return b, nil }
+func (b *scenarioBackend) VirtualNetwork() (virtualNetworkAPI, error) { return b, nil }
+func (b *scenarioBackend) ObjectStorage() (objectStorageAPI, error)   { return b, nil }
+func (b *scenarioBackend) LoadBalancer() (loadBalancerAPI, error)     { return b, nil }
+func (b *scenarioBackend) Database() (databaseAPI, error)             { return b, nil }
+func (b *scenarioBackend) Monitoring() (monitoringAPI, error)         { return b, nil }
+func (b *scenarioBackend) Identity() (identityAPI, error)             { return b, nil }
+func (b *scenarioBackend) Limits() (limitsAPI, error)                 { return b, nil }
+
+// callCount devuelve cuántas veces se llamó a una operación
+func (b *scenarioBackend) callCount(operation string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls[operation]
+}
+
+// call registra la llamada y devuelve el error configurado para la operación
+func (b *scenarioBackend) call(ctx context.Context, operation string) error {
+	b.mu.Lock()
+	b.calls[operation]++
+	e, failed := b.scenario.Errors[operation]
+	b.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed {
+		return e
+	}
+	return nil
+}
+
+// scenarioPage devuelve la página pedida y el token de la siguiente
+func scenarioPage[T any](items []T, limit *int, page *string) ([]T, *string) {
+	start := 0
+	if page != nil {
+		start, _ = strconv.Atoi(*page)
+	}
+	end := len(items)
+	if limit != nil && *limit > 0 && start+*limit < end {
+		end = start + *limit
+	}
+	if start > end {
+		start = end
+	}
+	var next *string
+	if end < len(items) {
+		next = common.String(strconv.Itoa(end))
+	}
+	return items[start:end], next
+}
+
+// inCompartment filtra los recursos de un compartimento
+func inCompartment[T any](items []T, compartmentID *string, compartmentOf func(T) *string) []T {
+	filtered := []T{}
+	for _, item := range items {
+		if c := compartmentOf(item); c != nil && compartmentID != nil && *c == *compartmentID {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+func (b *scenarioBackend) ListInstances(ctx context.Context, request core.ListInstancesRequest) (core.ListInstancesResponse, error) {
+	if err := b.call(ctx, "ListInstances"); err != nil {
+		return core.ListInstancesResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := []core.Instance{}
+	for _, instance := range inCompartment(b.scenario.Instances, request.CompartmentId, func(i core.Instance) *string { return i.CompartmentId }) {
+		if request.LifecycleState == "" || instance.LifecycleState == request.LifecycleState {
+			items = append(items, instance)
+		}
+	}
+	page, next := scenarioPage(items, request.Limit, request.Page)
+	return core.ListInstancesResponse{Items: page, OpcNextPage: next}, nil
+}
+
+// LaunchInstance añade la instancia al escenario en estado PROVISIONING
+// Como OCI, un opc-retry-token ya usado devuelve la misma instancia sin crear otra
+func (b *scenarioBackend) LaunchInstance(ctx context.Context, request core.LaunchInstanceRequest) (core.LaunchInstanceResponse, error) {
+	token := stringValue(request.OpcRetryToken)
+	b.mu.Lock()
+	if instance, ok := b.launched[token]; ok && token != "" {
+		b.mu.Unlock()
+		return core.LaunchInstanceResponse{Instance: instance}, nil
+	}
+	b.mu.Unlock()
+
+	details := request.LaunchInstanceDetails
+	var ad, fd string
+	if details.AvailabilityDomain != nil {
+		ad = *details.AvailabilityDomain
+	}
+	if details.FaultDomain != nil {
+		fd = *details.FaultDomain
+	}
+	if err := b.call(ctx, "LaunchInstance:"+ad+"/"+fd); err != nil {
+		return core.LaunchInstanceResponse{}, err
+	}
+	if err := b.call(ctx, "LaunchInstance"); err != nil {
+		return core.LaunchInstanceResponse{}, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	instance := core.Instance{
+		Id:                 common.String(fmt.Sprintf("ocid1.instance.oc1..stub%d", len(b.scenario.Instances)+1)),
+		DisplayName:        details.DisplayName,
+		CompartmentId:      details.CompartmentId,
+		AvailabilityDomain: details.AvailabilityDomain,
+		FaultDomain:        details.FaultDomain,
+		Shape:              details.Shape,
+		LifecycleState:     core.InstanceLifecycleStateProvisioning,
+	}
+	if details.ShapeConfig != nil {
+		instance.ShapeConfig = &core.InstanceShapeConfig{Ocpus: details.ShapeConfig.Ocpus, MemoryInGBs: details.ShapeConfig.MemoryInGBs}
+	}
+	b.scenario.Instances = append(b.scenario.Instances, instance)
+	if token != "" {
+		b.launched[token] = instance
+	}
+	return core.LaunchInstanceResponse{Instance: instance}, nil
+}
+
+// CreateComputeCapacityReport devuelve el estado de Capacity para el AD pedido
+func (b *scenarioBackend) CreateComputeCapacityReport(ctx context.Context, request core.CreateComputeCapacityReportRequest) (core.CreateComputeCapacityReportResponse, error) {
+	details := request.CreateComputeCapacityReportDetails
+	ad := ""
+	if details.AvailabilityDomain != nil {
+		ad = *details.AvailabilityDomain
+	}
+	if err := b.call(ctx, "CreateComputeCapacityReport:"+ad); err != nil {
+		return core.CreateComputeCapacityReportResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status, ok := b.scenario.Capacity[ad]
+	if !ok {
+		status = string(core.CapacityReportShapeAvailabilityAvailabilityStatusOutOfHostCapacity)
+	}
+	report := core.ComputeCapacityReport{
+		CompartmentId:      details.CompartmentId,
+		AvailabilityDomain: details.AvailabilityDomain,
+		TimeCreated:        &common.SDKTime{Time: time.Now()},
+	}
+	for _, shape := range details.ShapeAvailabilities {
+		availability := core.CapacityReportShapeAvailability{
+			InstanceShape:       shape.InstanceShape,
+			InstanceShapeConfig: shape.InstanceShapeConfig,
+			FaultDomain:         shape.FaultDomain,
+			AvailabilityStatus:  core.CapacityReportShapeAvailabilityAvailabilityStatusEnum(status),
+			AvailableCount:      common.Int64(0),
+		}
+		if status == string(core.CapacityReportShapeAvailabilityAvailabilityStatusAvailable) {
+			availability.AvailableCount = common.Int64(1)
+		}
+		report.ShapeAvailabilities = append(report.ShapeAvailabilities, availability)
+	}
+	return core.CreateComputeCapacityReportResponse{ComputeCapacityReport: report}, nil
+}
+
+func (b *scenarioBackend) ListBootVolumes(ctx context.Context, request core.ListBootVolumesRequest) (core.ListBootVolumesResponse, error) {
+	if err := b.call(ctx, "ListBootVolumes"); err != nil {
+		return core.ListBootVolumesResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := inCompartment(b.scenario.BootVolumes, request.CompartmentId, func(v core.BootVolume) *string { return v.CompartmentId })
+	page, next := scenarioPage(items, request.Limit, request.Page)
+	return core.ListBootVolumesResponse{Items: page, OpcNextPage: next}, nil
+}
+
+func (b *scenarioBackend) ListVolumes(ctx context.Context, request core.ListVolumesRequest) (core.ListVolumesResponse, error) {
+	if err := b.call(ctx, "ListVolumes"); err != nil {
+		return core.ListVolumesResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := inCompartment(b.scenario.Volumes, request.CompartmentId, func(v core.Volume) *string { return v.CompartmentId })
+	page, next := scenarioPage(items, request.Limit, request.Page)
+	return core.ListVolumesResponse{Items: page, OpcNextPage: next}, nil
+}
+
+func (b *scenarioBackend) ListPublicIps(ctx context.Context, request core.ListPublicIpsRequest) (core.ListPublicIpsResponse, error) {
+	if err := b.call(ctx, "ListPublicIps"); err != nil {
+		return core.ListPublicIpsResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := inCompartment(b.scenario.PublicIps, request.CompartmentId, func(ip core.PublicIp) *string { return ip.CompartmentId })
+	page, next := scenarioPage(items, request.Limit, request.Page)
+	return core.ListPublicIpsResponse{Items: page, OpcNextPage: next}, nil
+}
+
+func (b *scenarioBackend) GetNamespace(ctx context.Context, request objectstorage.GetNamespaceRequest) (objectstorage.GetNamespaceResponse, error) {
+	if err := b.call(ctx, "GetNamespace"); err != nil {
+		return objectstorage.GetNamespaceResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return objectstorage.GetNamespaceResponse{Value: common.String(b.scenario.Namespace)}, nil
+}
+
+func (b *scenarioBackend) ListBuckets(ctx context.Context, request objectstorage.ListBucketsRequest) (objectstorage.ListBucketsResponse, error) {
+	if err := b.call(ctx, "ListBuckets"); err != nil {
+		return objectstorage.ListBucketsResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	summaries := []objectstorage.BucketSummary{}
+	for _, bucket := range inCompartment(b.scenario.Buckets, request.CompartmentId, func(b objectstorage.Bucket) *string { return b.CompartmentId }) {
+		summaries = append(summaries, objectstorage.BucketSummary{
+			Namespace:     bucket.Namespace,
+			Name:          bucket.Name,
+			CompartmentId: bucket.CompartmentId,
+		})
+	}
+	page, next := scenarioPage(summaries, request.Limit, request.Page)
+	return objectstorage.ListBucketsResponse{Items: page, OpcNextPage: next}, nil
+}
+
+func (b *scenarioBackend) GetBucket(ctx context.Context, request objectstorage.GetBucketRequest) (objectstorage.GetBucketResponse, error) {
+	name := ""
+	if request.BucketName != nil {
+		name = *request.BucketName
+	}
+	if err := b.call(ctx, "GetBucket:"+name); err != nil {
+		return objectstorage.GetBucketResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, bucket := range b.scenario.Buckets {
+		if bucket.Name != nil && *bucket.Name == name {
+			return objectstorage.GetBucketResponse{Bucket: bucket}, nil
+		}
+	}
+	return objectstorage.GetBucketResponse{}, scenarioError{Status: http.StatusNotFound, Code: "BucketNotFound", Message: "Either the bucket named '" + name + "' does not exist in the namespace or you are not authorized to access it"}
+}
+
+func (b *scenarioBackend) ListLoadBalancers(ctx context.Context, request loadbalancer.ListLoadBalancersRequest) (loadbalancer.ListLoadBalancersResponse, error) {
+	if err := b.call(ctx, "ListLoadBalancers"); err != nil {
+		return loadbalancer.ListLoadBalancersResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := inCompartment(b.scenario.LoadBalancers, request.CompartmentId, func(lb loadbalancer.LoadBalancer) *string { return lb.CompartmentId })
+	var limit *int
+	if request.Limit != nil {
+		limit = common.Int(int(*request.Limit))
+	}
+	page, next := scenarioPage(items, limit, request.Page)
+	return loadbalancer.ListLoadBalancersResponse{Items: page, OpcNextPage: next}, nil
+}
+
+func (b *scenarioBackend) ListAutonomousDatabases(ctx context.Context, request database.ListAutonomousDatabasesRequest) (database.ListAutonomousDatabasesResponse, error) {
+	if err := b.call(ctx, "ListAutonomousDatabases"); err != nil {
+		return database.ListAutonomousDatabasesResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := inCompartment(b.scenario.AutonomousDatabases, request.CompartmentId, func(db database.AutonomousDatabaseSummary) *string { return db.CompartmentId })
+	page, next := scenarioPage(items, request.Limit, request.Page)
+	return database.ListAutonomousDatabasesResponse{Items: page, OpcNextPage: next}, nil
+}
+
+func (b *scenarioBackend) SummarizeMetricsData(ctx context.Context, request monitoring.SummarizeMetricsDataRequest) (monitoring.SummarizeMetricsDataResponse, error) {
+	key := metricKey(request.Namespace, request.Query)
+	if err := b.call(ctx, "SummarizeMetricsData:"+key); err != nil {
+		return monitoring.SummarizeMetricsDataResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := inCompartment(b.scenario.Metrics[key], request.CompartmentId, func(m monitoring.MetricData) *string { return m.CompartmentId })
+	return monitoring.SummarizeMetricsDataResponse{Items: items}, nil
+}
+
+func (b *scenarioBackend) ListAvailabilityDomains(ctx context.Context, request identity.ListAvailabilityDomainsRequest) (identity.ListAvailabilityDomainsResponse, error) {
+	if err := b.call(ctx, "ListAvailabilityDomains"); err != nil {
+		return identity.ListAvailabilityDomainsResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return identity.ListAvailabilityDomainsResponse{Items: b.scenario.AvailabilityDomains}, nil
+}
+
+func (b *scenarioBackend) ListFaultDomains(ctx context.Context, request identity.ListFaultDomainsRequest) (identity.ListFaultDomainsResponse, error) {
+	if err := b.call(ctx, "ListFaultDomains"); err != nil {
+		return identity.ListFaultDomainsResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := []identity.FaultDomain{}
+	for _, fd := range b.scenario.FaultDomains {
+		if fd.AvailabilityDomain != nil && request.AvailabilityDomain != nil && *fd.AvailabilityDomain == *request.AvailabilityDomain {
+			items = append(items, fd)
+		}
+	}
+	return identity.ListFaultDomainsResponse{Items: items}, nil
+}
+
+// ListCompartments devuelve los hijos directos del compartimento o, con
+// CompartmentIdInSubtree, todos los compartimentos del escenario (solo se permite en la raíz)
+func (b *scenarioBackend) ListCompartments(ctx context.Context, request identity.ListCompartmentsRequest) (identity.ListCompartmentsResponse, error) {
+	if err := b.call(ctx, "ListCompartments"); err != nil {
+		return identity.ListCompartmentsResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subtree := request.CompartmentIdInSubtree != nil && *request.CompartmentIdInSubtree
+	items := []identity.Compartment{}
+	for _, c := range b.scenario.Compartments {
+		if request.LifecycleState != "" && c.LifecycleState != request.LifecycleState {
+			continue
+		}
+		if subtree || stringValue(c.CompartmentId) == stringValue(request.CompartmentId) {
+			items = append(items, c)
+		}
+	}
+	page, next := scenarioPage(items, request.Limit, request.Page)
+	return identity.ListCompartmentsResponse{Items: page, OpcNextPage: next}, nil
+}
+
+func (b *scenarioBackend) ListRegionSubscriptions(ctx context.Context, request identity.ListRegionSubscriptionsRequest) (identity.ListRegionSubscriptionsResponse, error) {
+	if err := b.call(ctx, "ListRegionSubscriptions"); err != nil {
+		return identity.ListRegionSubscriptionsResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return identity.ListRegionSubscriptionsResponse{Items: b.scenario.RegionSubscriptions}, nil
+}
+
+func (b *scenarioBackend) ListLimitValues(ctx context.Context, request limits.ListLimitValuesRequest) (limits.ListLimitValuesResponse, error) {
+	if err := b.call(ctx, "ListLimitValues"); err != nil {
+		return limits.ListLimitValuesResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := []limits.LimitValueSummary{}
+	for _, value := range b.scenario.Limits[stringValue(request.ServiceName)+"/"+stringValue(request.Name)] {
+		items = append(items, value.LimitValueSummary)
+	}
+	page, next := scenarioPage(items, request.Limit, request.Page)
+	return limits.ListLimitValuesResponse{Items: page, OpcNextPage: next}, nil
+}
+
+// GetResourceAvailability devuelve el uso del ámbito pedido: el AD o, sin AD, la región
+func (b *scenarioBackend) GetResourceAvailability(ctx context.Context, request limits.GetResourceAvailabilityRequest) (limits.GetResourceAvailabilityResponse, error) {
+	if err := b.call(ctx, "GetResourceAvailability"); err != nil {
+		return limits.GetResourceAvailabilityResponse{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := stringValue(request.ServiceName) + "/" + stringValue(request.LimitName)
+	for _, value := range b.scenario.Limits[key] {
+		if stringValue(value.AvailabilityDomain) == stringValue(request.AvailabilityDomain) {
+			return limits.GetResourceAvailabilityResponse{ResourceAvailability: limits.ResourceAvailability{
+				Used:      value.Used,
+				Available: value.Available,
+			}}, nil
+		}
+	}
+	return limits.GetResourceAvailabilityResponse{}, scenarioError{Status: http.StatusNotFound, Code: "NotAuthorizedOrNotFound", Message: "limit " + key + " not found"}
+}
+
+// metricKey identifica una consulta MQL por namespace y métrica ("oci_vnic/VnicToNetworkBytes")
+// El nombre de la métrica es lo que va antes del intervalo o del filtro de dimensiones
+func metricKey(namespace, query *string) string {
+	var ns, name string
+	if namespace != nil {
+		ns = *namespace
+	}
+	if query != nil {
+		name = *query
+		if i := strings.IndexAny(name, "[{"); i >= 0 {
+			name = name[:i]
+		}
+	}
+	return ns + "/" + strings.TrimSpace(name)
+}
+
+// ociStubHandler sirve el escenario con las rutas REST de OCI que usa el watcher
+// No comprueba la firma de las peticiones: cualquier credencial es válida
+func ociStubHandler(backend *scenarioBackend) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Todas las rutas son de lectura salvo las consultas de Monitoring, LaunchInstance y
+		// los informes de capacidad, que van por POST
+		isMetricsQuery := r.URL.Path == "/20180401/metrics/actions/summarizeMetricsData"
+		isLaunch := r.URL.Path == "/20160918/instances" && r.Method == http.MethodPost
+		isCapacityReport := r.URL.Path == "/20160918/computeCapacityReports"
+		isPost := isMetricsQuery || isLaunch || isCapacityReport
+		if r.Method != http.MethodGet && !(isPost && r.Method == http.MethodPost) {
+			writeStubError(w, scenarioError{Status: http.StatusMethodNotAllowed, Code: "MethodNotAllowed", Message: "method not supported by the stub"})
+			return
+		}
+
+		query := r.URL.Query()
+		compartmentID := optionalQuery(query.Get("compartmentId"))
+		page := optionalQuery(query.Get("page"))
+		var limit *int
+		if n, err := strconv.Atoi(query.Get("limit")); err == nil {
+			limit = common.Int(n)
+		}
+
+		// En Go, un switch sin expresión es una cadena de if/else más legible
+		var (
+			items any
+			next  *string
+			err   error
+		)
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case isLaunch:
+			var details core.LaunchInstanceDetails
+			if err = json.NewDecoder(r.Body).Decode(&details); err != nil {
+				err = scenarioError{Status: http.StatusBadRequest, Code: "InvalidParameter", Message: err.Error()}
+				break
+			}
+			var response core.LaunchInstanceResponse
+			response, err = backend.LaunchInstance(r.Context(), core.LaunchInstanceRequest{
+				LaunchInstanceDetails: details,
+				OpcRetryToken:         common.String(r.Header.Get("opc-retry-token")),
+			})
+			items = response.Instance
+		case isCapacityReport:
+			var details core.CreateComputeCapacityReportDetails
+			if err = json.NewDecoder(r.Body).Decode(&details); err != nil {
+				err = scenarioError{Status: http.StatusBadRequest, Code: "InvalidParameter", Message: err.Error()}
+				break
+			}
+			var response core.CreateComputeCapacityReportResponse
+			response, err = backend.CreateComputeCapacityReport(r.Context(), core.CreateComputeCapacityReportRequest{CreateComputeCapacityReportDetails: details})
+			items = response.ComputeCapacityReport
+		case r.URL.Path == "/20160918/availabilityDomains":
+			var response identity.ListAvailabilityDomainsResponse
+			response, err = backend.ListAvailabilityDomains(r.Context(), identity.ListAvailabilityDomainsRequest{CompartmentId: compartmentID})
+			items = response.Items
+		case r.URL.Path == "/20160918/faultDomains":
+			var response identity.ListFaultDomainsResponse
+			response, err = backend.ListFaultDomains(r.Context(), identity.ListFaultDomainsRequest{
+				CompartmentId:      compartmentID,
+				AvailabilityDomain: optionalQuery(query.Get("availabilityDomain")),
+			})
+			items = response.Items
+		case r.URL.Path == "/20160918/compartments":
+			var response identity.ListCompartmentsResponse
+			response, err = backend.ListCompartments(r.Context(), identity.ListCompartmentsRequest{
+				CompartmentId:          compartmentID,
+				CompartmentIdInSubtree: common.Bool(query.Get("compartmentIdInSubtree") == "true"),
+				LifecycleState:         identity.CompartmentLifecycleStateEnum(query.Get("lifecycleState")),
+				Limit:                  limit,
+				Page:                   page,
+			})
+			items, next = response.Items, response.OpcNextPage
+		case len(parts) == 4 && parts[0] == "20160918" && parts[1] == "tenancies" && parts[3] == "regionSubscriptions":
+			var response identity.ListRegionSubscriptionsResponse
+			response, err = backend.ListRegionSubscriptions(r.Context(), identity.ListRegionSubscriptionsRequest{TenancyId: common.String(parts[2])})
+			items = response.Items
+		case r.URL.Path == "/20190729/limitValues":
+			var response limits.ListLimitValuesResponse
+			response, err = backend.ListLimitValues(r.Context(), limits.ListLimitValuesRequest{
+				CompartmentId: compartmentID,
+				ServiceName:   optionalQuery(query.Get("serviceName")),
+				Name:          optionalQuery(query.Get("name")),
+				Limit:         limit,
+				Page:          page,
+			})
+			items, next = response.Items, response.OpcNextPage
+		case len(parts) == 6 && parts[0] == "20190729" && parts[1] == "services" && parts[3] == "limits" && parts[5] == "resourceAvailability":
+			var response limits.GetResourceAvailabilityResponse
+			response, err = backend.GetResourceAvailability(r.Context(), limits.GetResourceAvailabilityRequest{
+				ServiceName:        common.String(parts[2]),
+				LimitName:          common.String(parts[4]),
+				CompartmentId:      compartmentID,
+				AvailabilityDomain: optionalQuery(query.Get("availabilityDomain")),
+			})
+			items = response.ResourceAvailability
+		case r.URL.Path == "/20160918/instances":
+			var response core.ListInstancesResponse
+			response, err = backend.ListInstances(r.Context(), core.ListInstancesRequest{
+				CompartmentId:  compartmentID,
+				LifecycleState: core.InstanceLifecycleStateEnum(query.Get("lifecycleState")),
+				Limit:          limit,
+				Page:           page,
+			})
+			items, next = response.Items, response.OpcNextPage
+		case r.URL.Path == "/20160918/bootVolumes":
+			var response core.ListBootVolumesResponse
+			response, err = backend.ListBootVolumes(r.Context(), core.ListBootVolumesRequest{CompartmentId: compartmentID, Limit: limit, Page: page})
+			items, next = response.Items, response.OpcNextPage
+		case r.URL.Path == "/20160918/volumes":
+			var response core.ListVolumesResponse
+			response, err = backend.ListVolumes(r.Context(), core.ListVolumesRequest{CompartmentId: compartmentID, Limit: limit, Page: page})
+			items, next = response.Items, response.OpcNextPage
+		case r.URL.Path == "/20160918/publicIps":
+			var response core.ListPublicIpsResponse
+			response, err = backend.ListPublicIps(r.Context(), core.ListPublicIpsRequest{CompartmentId: compartmentID, Limit: limit, Page: page})
+			items, next = response.Items, response.OpcNextPage
+		case r.URL.Path == "/20160918/autonomousDatabases":
+			var response database.ListAutonomousDatabasesResponse
+			response, err = backend.ListAutonomousDatabases(r.Context(), database.ListAutonomousDatabasesRequest{CompartmentId: compartmentID, Limit: limit, Page: page})
+			items, next = response.Items, response.OpcNextPage
+		case r.URL.Path == "/20170115/loadBalancers":
+			request := loadbalancer.ListLoadBalancersRequest{CompartmentId: compartmentID, Page: page}
+			if limit != nil {
+				request.Limit = common.Int64(int64(*limit))
+			}
+			var response loadbalancer.ListLoadBalancersResponse
+			response, err = backend.ListLoadBalancers(r.Context(), request)
+			items, next = response.Items, response.OpcNextPage
+		case isMetricsQuery:
+			var details monitoring.SummarizeMetricsDataDetails
+			if err = json.NewDecoder(r.Body).Decode(&details); err != nil {
+				err = scenarioError{Status: http.StatusBadRequest, Code: "InvalidParameter", Message: err.Error()}
+				break
+			}
+			var response monitoring.SummarizeMetricsDataResponse
+			response, err = backend.SummarizeMetricsData(r.Context(), monitoring.SummarizeMetricsDataRequest{
+				CompartmentId:               compartmentID,
+				SummarizeMetricsDataDetails: details,
+			})
+			items = response.Items
+		case r.URL.Path == "/n":
+			var response objectstorage.GetNamespaceResponse
+			response, err = backend.GetNamespace(r.Context(), objectstorage.GetNamespaceRequest{})
+			items = response.Value
+		case len(parts) == 3 && parts[0] == "n" && parts[2] == "b":
+			var response objectstorage.ListBucketsResponse
+			response, err = backend.ListBuckets(r.Context(), objectstorage.ListBucketsRequest{
+				NamespaceName: common.String(parts[1]),
+				CompartmentId: compartmentID,
+				Limit:         limit,
+				Page:          page,
+			})
+			items, next = response.Items, response.OpcNextPage
+		case len(parts) == 4 && parts[0] == "n" && parts[2] == "b":
+			var response objectstorage.GetBucketResponse
+			response, err = backend.GetBucket(r.Context(), objectstorage.GetBucketRequest{
+				NamespaceName: common.String(parts[1]),
+				BucketName:    common.String(parts[3]),
+			})
+			items = response.Bucket
+		default:
+			err = scenarioError{Status: http.StatusNotFound, Code: "NotAuthorizedOrNotFound", Message: "the stub does not implement " + r.URL.Path}
+		}
+
+		logger.Debug().Str("method", r.Method).Str("path", r.URL.Path).Err(err).Msg("OCI stub request")
+		if err != nil {
+			writeStubError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("opc-request-id", "stub")
+		if next != nil {
+			w.Header().Set("opc-next-page", *next)
+		}
+		json.NewEncoder(w).Encode(items)
+	})
+}
+
+// optionalQuery devuelve nil para un parámetro vacío, como hace el SDK
+func optionalQuery(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return common.String(value)
+}
+
+// writeStubError escribe un error con el formato de OCI ({"code","message"} y opc-request-id)
+func writeStubError(w http.ResponseWriter, err error) {
+	e, ok := err.(scenarioError)
+	if !ok {
+		e = scenarioError{Status: http.StatusInternalServerError, Code: "InternalServerError", Message: err.Error()}
+	}
+	if e.OpcRequestID == "" {
+		e.OpcRequestID = "stub"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("opc-request-id", e.OpcRequestID)
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(map[string]string{"code": e.Code, "message": e.Message})
+}
+
+// runStubCommand arranca el doble de OCI con un escenario
+// Se combina con OCI_ENDPOINT_OVERRIDE para probar el watcher sin una cuenta de OCI
+func runStubCommand(args []string, stdout io.Writer) int {
+	flags := flag.NewFlagSet("stub", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	addr := flags.String("addr", "127.0.0.1:9999", "address to listen on")
+	scenarioPath := flags.String("scenario", "", "scenario JSON file (see testdata/)")
+	if err := flags.Parse(args); err != nil {
+		return exitUnknown
+	}
+	if *scenarioPath == "" {
+		fmt.Fprintln(stdout, "ERROR: -scenario is required")
+		return exitUnknown
+	}
+
+	scenario, err := loadScenario(*scenarioPath)
+	if err != nil {
+		fmt.Fprintf(stdout, "ERROR: %v\n", err)
+		return exitUnknown
+	}
+
+	logger.Info().Str("addr", *addr).Str("scenario", *scenarioPath).Msg("OCI stub listening")
+	fmt.Fprintf(stdout, "Set OCI_ENDPOINT_OVERRIDE=http://%s to use this stub\n", *addr)
+	if err := http.ListenAndServe(*addr, ociStubHandler(newScenarioBackend(scenario))); err != nil {
+		fmt.Fprintf(stdout, "ERROR: %v\n", err)
+		return exitUnknown
+	}
+	return exitOK
+}
@@ -0,0 +1,34 @@
+// Package main - Este archivo contiene la paginación común de las llamadas List de OCI
+package main
+
+// paginationConfig controla el tamaño de página y el tope de seguridad de páginas
+type paginationConfig struct {
+	PageLimit int // elementos por página (parámetro limit de OCI)
+	MaxPages  int // máximo de páginas por llamada; si se alcanza el resultado se marca como truncado
+}
+
+// currentPagination lee la configuración de paginación del entorno
+func currentPagination() paginationConfig {
+	return paginationConfig{
+		PageLimit: getEnvInt("OCI_PAGE_LIMIT", 100),
+		MaxPages:  getEnvInt("OCI_MAX_PAGES", 50),
+	}
+}
+
+// paginate llama a fetch siguiendo OpcNextPage hasta la última página
+// fetch recibe el token de la página a pedir (nil la primera vez) y devuelve el siguiente
+// Devuelve truncated=true si se alcanzó MaxPages y aún quedaban páginas
+func (c paginationConfig) paginate(fetch func(page *string) (next *string, err error)) (bool, error) {
+	var page *string
+	for pages := 0; pages < c.MaxPages; pages++ {
+		next, err := fetch(page)
+		if err != nil {
+			return false, err
+		}
+		if next == nil || *next == "" {
+			return false, nil
+		}
+		page = next
+	}
+	return true, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// fakePages simula una llamada List de OCI con el número de páginas indicado
+func fakePages(total int, calls *int) func(page *string) (*string, error) {
+	return func(page *string) (*string, error) {
+		*calls++
+		if *calls >= total {
+			return nil, nil
+		}
+		next := fmt.Sprintf("page-%d", *calls+1)
+		return &next, nil
+	}
+}
+
+// TestPaginate verifica que se sigue el token de página hasta el final o hasta MaxPages
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name          string
+		totalPages    int
+		maxPages      int
+		wantCalls     int
+		wantTruncated bool
+	}{
+		{name: "una sola página", totalPages: 1, maxPages: 50, wantCalls: 1},
+		{name: "sigue la página siguiente", totalPages: 3, maxPages: 50, wantCalls: 3},
+		{name: "justo en el tope", totalPages: 5, maxPages: 5, wantCalls: 5},
+		{name: "tope alcanzado", totalPages: 10, maxPages: 5, wantCalls: 5, wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			cfg := paginationConfig{PageLimit: 100, MaxPages: tt.maxPages}
+			truncated, err := cfg.paginate(fakePages(tt.totalPages, &calls))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d; want %d", calls, tt.wantCalls)
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v; want %v", truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+// TestPaginatePassesPageToken verifica que cada llamada recibe el token devuelto por la anterior
+func TestPaginatePassesPageToken(t *testing.T) {
+	var seen []string
+	cfg := paginationConfig{PageLimit: 100, MaxPages: 50}
+	_, err := cfg.paginate(func(page *string) (*string, error) {
+		if page == nil {
+			seen = append(seen, "")
+			next := "abc"
+			return &next, nil
+		}
+		seen = append(seen, *page)
+		empty := ""
+		return &empty, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seen) != 2 || seen[0] != "" || seen[1] != "abc" {
+		t.Errorf("page tokens = %q; want [\"\" \"abc\"]", seen)
+	}
+}
+
+// TestPaginateError verifica que un error corta la paginación y no se marca como truncado
+func TestPaginateError(t *testing.T) {
+	calls := 0
+	boom := errors.New("boom")
+	cfg := paginationConfig{PageLimit: 100, MaxPages: 50}
+	truncated, err := cfg.paginate(func(page *string) (*string, error) {
+		calls++
+		if calls == 2 {
+			return nil, boom
+		}
+		next := "next"
+		return &next, nil
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v; want %v", err, boom)
+	}
+	if truncated {
+		t.Error("truncated should be false on error")
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d; want 2", calls)
+	}
+}
+
+// TestCurrentPagination verifica que las variables vacías usan el valor por defecto
+func TestCurrentPagination(t *testing.T) {
+	t.Setenv("OCI_PAGE_LIMIT", "25")
+	t.Setenv("OCI_MAX_PAGES", "")
+	cfg := currentPagination()
+	if cfg.PageLimit != 25 || cfg.MaxPages != 50 {
+		t.Errorf("currentPagination() = %+v; want {25 50}", cfg)
+	}
+}
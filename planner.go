@@ -0,0 +1,298 @@
+// Package main - Este archivo implementa el planificador: calcula lo que queda de Free Tier
+// para compute y almacenamiento en bloque, sugiere instancias que caben enteras y responde
+// si un conjunto de cambios propuesto se saldría de la Free Tier (POST /plan)
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// microShape es la única shape AMD Always Free (1 OCPU y 1 GB fijos)
+	microShape = "VM.Standard.E2.1.Micro"
+	// a1MaxMemoryPerOCPU es la memoria máxima por OCPU que admite VM.Standard.A1.Flex
+	a1MaxMemoryPerOCPU = 64
+)
+
+// Headroom es lo que queda libre de la Free Tier; un valor negativo indica exceso
+type Headroom struct {
+	ARMOCPUs       float64 `json:"armOcpus"`
+	ARMMemoryGB    float64 `json:"armMemoryGB"`
+	ARMInstances   int     `json:"armInstances"`
+	AMDInstances   int     `json:"amdInstances"`
+	BlockStorageGB float64 `json:"blockStorageGB"`
+}
+
+// InstanceSuggestion es una configuración que cabe entera en lo que queda:
+// Count instancias iguales de ese tamaño, cada una con su boot volume
+type InstanceSuggestion struct {
+	Shape        string  `json:"shape"`
+	Count        int     `json:"count"`
+	OCPUs        float64 `json:"ocpus"`
+	MemoryGB     float64 `json:"memoryGB"`
+	BootVolumeGB int     `json:"bootVolumeGB"`
+}
+
+// PlanChange es un cambio propuesto
+//   - {"action":"add","type":"instance","ocpus":2,"memoryGB":12,"bootVolumeGB":50,"count":1}
+//   - {"action":"add","type":"volume","sizeGB":100}
+//   - {"action":"remove","resourceId":"ocid1.instance..."} (instancia o volumen existente)
+type PlanChange struct {
+	Action       string  `json:"action"` // "add" (por defecto) o "remove"
+	Type         string  `json:"type"`   // "instance" o "volume" (solo en add)
+	Shape        string  `json:"shape,omitempty"`
+	OCPUs        float64 `json:"ocpus,omitempty"`
+	MemoryGB     float64 `json:"memoryGB,omitempty"`
+	BootVolumeGB int     `json:"bootVolumeGB,omitempty"`
+	SizeGB       int     `json:"sizeGB,omitempty"`
+	Count        int     `json:"count,omitempty"` // por defecto 1
+	ResourceID   string  `json:"resourceId,omitempty"`
+}
+
+// PlanRequest es el cuerpo de POST /plan
+type PlanRequest struct {
+	Changes []PlanChange `json:"changes"`
+}
+
+// PlanResult es la respuesta de /plan
+type PlanResult struct {
+	Fits        bool                 `json:"fits"`
+	Headroom    Headroom             `json:"headroom"`           // antes de los cambios
+	After       Headroom             `json:"after"`              // después de los cambios
+	Exceeded    []string             `json:"exceeded,omitempty"` // qué límites se superarían
+	Suggestions []InstanceSuggestion `json:"suggestions"`        // lo que aún cabe tras los cambios
+	Timestamp   string               `json:"timestamp"`
+}
+
+// errPlanUnavailable indica que el uso no es fiable para planificar
+var errPlanUnavailable = errors.New("usage incomplete, cannot plan")
+
+// computeHeadroom calcula lo que queda libre a partir del uso y los límites
+// Lo comparten el planificador y el lanzador: un límite de 0 significa que no queda nada
+func computeHeadroom(compute ComputeUsage, storage StorageUsage, limits FreeTierLimits) Headroom {
+	return Headroom{
+		ARMOCPUs:       limits.Compute.ARM.OCPUs - compute.ARM.OCPUs.Used,
+		ARMMemoryGB:    limits.Compute.ARM.MemoryGB - compute.ARM.MemoryGB.Used,
+		ARMInstances:   limits.Compute.ARM.MaxInstances - compute.ARM.Instances,
+		AMDInstances:   limits.Compute.AMD.MaxInstances - int(compute.AMD.Instances.Used),
+		BlockStorageGB: float64(limits.BlockStorage.TotalGB) - storage.Total.Used,
+	}
+}
+
+// exceeded lista los recursos con headroom negativo
+func (h Headroom) exceeded() []string {
+	var problems []string
+	if h.ARMOCPUs < 0 {
+		problems = append(problems, fmt.Sprintf("compute.arm.ocpus exceeded by %g", -h.ARMOCPUs))
+	}
+	if h.ARMMemoryGB < 0 {
+		problems = append(problems, fmt.Sprintf("compute.arm.memoryGB exceeded by %g", -h.ARMMemoryGB))
+	}
+	if h.ARMInstances < 0 {
+		problems = append(problems, fmt.Sprintf("compute.arm.instances exceeded by %d", -h.ARMInstances))
+	}
+	if h.AMDInstances < 0 {
+		problems = append(problems, fmt.Sprintf("compute.amd.instances exceeded by %d", -h.AMDInstances))
+	}
+	if h.BlockStorageGB < 0 {
+		problems = append(problems, fmt.Sprintf("blockStorage.total exceeded by %g GB", -h.BlockStorageGB))
+	}
+	return problems
+}
+
+// suggestConfigurations lista configuraciones que caben enteras en el headroom
+// Para A1 reparte lo que queda en 1, 2, ... instancias iguales (OCPUs enteras, entre 1 y 64 GB
+// por OCPU); para Micro, tantas como queden. Cada sugerencia es una alternativa: todas
+// comparten el mismo almacenamiento en bloque
+func suggestConfigurations(h Headroom) []InstanceSuggestion {
+	suggestions := []InstanceSuggestion{}
+	storage := int(math.Floor(h.BlockStorageGB))
+
+	maxARM := h.ARMInstances
+	if ocpus := int(math.Floor(h.ARMOCPUs)); ocpus < maxARM {
+		maxARM = ocpus
+	}
+	for n := 1; n <= maxARM; n++ {
+		ocpus := math.Floor(h.ARMOCPUs / float64(n))
+		memory := math.Min(math.Floor(h.ARMMemoryGB/float64(n)), ocpus*a1MaxMemoryPerOCPU)
+		boot := storage / n
+		if ocpus < 1 || memory < ocpus || boot < minBootVolumeGB {
+			continue
+		}
+		suggestions = append(suggestions, InstanceSuggestion{
+			Shape: defaultLaunchShape, Count: n, OCPUs: ocpus, MemoryGB: memory, BootVolumeGB: boot,
+		})
+	}
+
+	for n := 1; n <= h.AMDInstances; n++ {
+		boot := storage / n
+		if boot < minBootVolumeGB {
+			break
+		}
+		suggestions = append(suggestions, InstanceSuggestion{
+			Shape: microShape, Count: n, OCPUs: 1, MemoryGB: 1, BootVolumeGB: boot,
+		})
+	}
+	return suggestions
+}
+
+// applyChange resta (add) o suma (remove) un cambio al headroom
+// Devuelve un error si el cambio está mal formado; los excesos se ven después en el headroom
+func applyChange(h *Headroom, change PlanChange, compute ComputeUsage, storage StorageUsage) error {
+	count := change.Count
+	if count == 0 {
+		count = 1
+	}
+	if count < 0 {
+		return fmt.Errorf("count must be positive")
+	}
+
+	switch strings.ToLower(change.Action) {
+	case "", "add":
+		switch strings.ToLower(change.Type) {
+		case "instance":
+			shape := change.Shape
+			if shape == "" {
+				shape = defaultLaunchShape
+			}
+			boot := change.BootVolumeGB
+			if boot == 0 {
+				boot = minBootVolumeGB
+			}
+			if boot < minBootVolumeGB {
+				return fmt.Errorf("bootVolumeGB must be at least %d", minBootVolumeGB)
+			}
+			// Solo A1.Flex y E2.1.Micro son gratis (ver classifyInstance): una bare metal
+			// A1 también contiene "A1" pero se factura
+			if billing, reason := classifyInstance(shape); billing == BillingBillable {
+				return errors.New(reason)
+			}
+			if shape == microShape {
+				h.AMDInstances -= count
+			} else {
+				if change.OCPUs <= 0 || change.MemoryGB <= 0 {
+					return fmt.Errorf("ocpus and memoryGB are required for %s", shape)
+				}
+				h.ARMOCPUs -= change.OCPUs * float64(count)
+				h.ARMMemoryGB -= change.MemoryGB * float64(count)
+				h.ARMInstances -= count
+			}
+			h.BlockStorageGB -= float64(boot * count)
+		case "volume":
+			if change.SizeGB <= 0 {
+				return fmt.Errorf("sizeGB is required for a volume")
+			}
+			h.BlockStorageGB -= float64(change.SizeGB * count)
+		default:
+			return fmt.Errorf("unknown type %q (use instance or volume)", change.Type)
+		}
+	case "remove":
+		if change.ResourceID == "" {
+			return fmt.Errorf("resourceId is required to remove a resource")
+		}
+		for _, instance := range compute.Instances {
+			if instance.ID != change.ResourceID {
+				continue
+			}
+			switch instance.Arch {
+			case "arm":
+				h.ARMOCPUs += instance.OCPUs
+				h.ARMMemoryGB += instance.MemoryGB
+				h.ARMInstances++
+			case "amd":
+				h.AMDInstances++
+			}
+			return nil
+		}
+		for _, volume := range storage.Volumes {
+			if volume.ID == change.ResourceID {
+				h.BlockStorageGB += float64(volume.SizeGB)
+				return nil
+			}
+		}
+		return fmt.Errorf("resource %s not found in current usage", change.ResourceID)
+	default:
+		return fmt.Errorf("unknown action %q (use add or remove)", change.Action)
+	}
+	return nil
+}
+
+// evaluatePlan aplica los cambios al headroom actual y dice si todo cabe en la Free Tier
+func evaluatePlan(compute ComputeUsage, storage StorageUsage, limits FreeTierLimits, changes []PlanChange) (PlanResult, error) {
+	before := computeHeadroom(compute, storage, limits)
+	after := before
+	for i, change := range changes {
+		if err := applyChange(&after, change, compute, storage); err != nil {
+			return PlanResult{}, fmt.Errorf("change %d: %w", i+1, err)
+		}
+	}
+
+	result := PlanResult{
+		Headroom:    before,
+		After:       after,
+		Exceeded:    after.exceeded(),
+		Suggestions: []InstanceSuggestion{},
+	}
+	result.Fits = len(result.Exceeded) == 0
+	if result.Fits {
+		result.Suggestions = suggestConfigurations(after)
+	}
+	return result, nil
+}
+
+// planUsage devuelve el uso en caché para planificar
+// Si compute o el almacenamiento en bloque fallaron, el plan no sería fiable
+func planUsage(r *http.Request) (*AllUsage, error) {
+	usage, _, err := poller.Snapshot(r.Context(), wantsRefresh(r))
+	if err != nil {
+		return nil, err
+	}
+	if usage.Compute.Error != "" || usage.Compute.Truncated || usage.BlockStorage.Error != "" || usage.BlockStorage.Truncated {
+		return nil, errPlanUnavailable
+	}
+	return usage, nil
+}
+
+// planHandler maneja /plan
+// GET devuelve el headroom actual y las configuraciones que caben;
+// POST evalúa un conjunto de cambios ({"changes":[...]})
+func planHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isConfigured() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "OCI not configured"})
+		return
+	}
+
+	var request PlanRequest
+	if r.Method == http.MethodPost {
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&request); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid plan: " + err.Error()})
+			return
+		}
+	}
+
+	usage, err := planUsage(r)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		return
+	}
+
+	result, err := evaluatePlan(usage.Compute, usage.BlockStorage, currentLimits(), request.Changes)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	result.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	writeJSON(w, http.StatusOK, result)
+}
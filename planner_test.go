@@ -0,0 +1,151 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// plannerUsage es un uso con 1 instancia A1 (1 OCPU, 6 GB), 1 Micro y 100 GB de bloque
+func plannerUsage() (ComputeUsage, StorageUsage) {
+	var compute ComputeUsage
+	compute.ARM.OCPUs.Used = 1
+	compute.ARM.MemoryGB.Used = 6
+	compute.ARM.Instances = 1
+	compute.AMD.Instances.Used = 1
+	compute.Instances = []InstanceInfo{
+		{ID: "ocid1.instance.arm", Arch: "arm", OCPUs: 1, MemoryGB: 6},
+		{ID: "ocid1.instance.micro", Arch: "amd", OCPUs: 1, MemoryGB: 1},
+	}
+	var storage StorageUsage
+	storage.Total.Used = 100
+	storage.Volumes = []VolumeInfo{
+		{ID: "ocid1.bootvolume.arm", Type: "boot", SizeGB: 50},
+		{ID: "ocid1.bootvolume.micro", Type: "boot", SizeGB: 50},
+	}
+	return compute, storage
+}
+
+func TestComputeHeadroom(t *testing.T) {
+	compute, storage := plannerUsage()
+	got := computeHeadroom(compute, storage, currentLimits())
+	want := Headroom{ARMOCPUs: 3, ARMMemoryGB: 18, ARMInstances: 3, AMDInstances: 1, BlockStorageGB: 100}
+	if got != want {
+		t.Errorf("computeHeadroom = %+v; want %+v", got, want)
+	}
+}
+
+func TestSuggestConfigurations(t *testing.T) {
+	tests := []struct {
+		name     string
+		headroom Headroom
+		want     []InstanceSuggestion
+	}{
+		{
+			name:     "Free Tier vacía",
+			headroom: Headroom{ARMOCPUs: 4, ARMMemoryGB: 24, ARMInstances: 4, AMDInstances: 2, BlockStorageGB: 200},
+			want: []InstanceSuggestion{
+				{Shape: defaultLaunchShape, Count: 1, OCPUs: 4, MemoryGB: 24, BootVolumeGB: 200},
+				{Shape: defaultLaunchShape, Count: 2, OCPUs: 2, MemoryGB: 12, BootVolumeGB: 100},
+				{Shape: defaultLaunchShape, Count: 3, OCPUs: 1, MemoryGB: 8, BootVolumeGB: 66},
+				{Shape: defaultLaunchShape, Count: 4, OCPUs: 1, MemoryGB: 6, BootVolumeGB: 50},
+				{Shape: microShape, Count: 1, OCPUs: 1, MemoryGB: 1, BootVolumeGB: 200},
+				{Shape: microShape, Count: 2, OCPUs: 1, MemoryGB: 1, BootVolumeGB: 100},
+			},
+		},
+		{
+			name:     "el almacenamiento limita el número de instancias",
+			headroom: Headroom{ARMOCPUs: 3, ARMMemoryGB: 18, ARMInstances: 3, BlockStorageGB: 100},
+			want: []InstanceSuggestion{
+				{Shape: defaultLaunchShape, Count: 1, OCPUs: 3, MemoryGB: 18, BootVolumeGB: 100},
+				{Shape: defaultLaunchShape, Count: 2, OCPUs: 1, MemoryGB: 9, BootVolumeGB: 50},
+			},
+		},
+		{
+			name:     "sin almacenamiento no cabe nada",
+			headroom: Headroom{ARMOCPUs: 4, ARMMemoryGB: 24, ARMInstances: 4, AMDInstances: 2, BlockStorageGB: 40},
+			want:     []InstanceSuggestion{},
+		},
+		{
+			name:     "memoria por debajo de 1 GB por OCPU",
+			headroom: Headroom{ARMOCPUs: 2, ARMMemoryGB: 1, ARMInstances: 2, BlockStorageGB: 200},
+			want:     []InstanceSuggestion{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestConfigurations(tt.headroom); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("suggestConfigurations =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluatePlan(t *testing.T) {
+	compute, storage := plannerUsage()
+	tests := []struct {
+		name         string
+		changes      []PlanChange
+		wantFits     bool
+		wantExceeded string
+		wantErr      string
+	}{
+		{name: "sin cambios", wantFits: true},
+		{
+			name:     "cabe una A1 de 3 OCPUs",
+			changes:  []PlanChange{{Type: "instance", OCPUs: 3, MemoryGB: 18}},
+			wantFits: true,
+		},
+		{
+			name:         "dos A1 de 2 OCPUs no caben",
+			changes:      []PlanChange{{Type: "instance", OCPUs: 2, MemoryGB: 12, Count: 2}},
+			wantExceeded: "compute.arm.ocpus exceeded by 1",
+		},
+		{
+			name: "cabe si se elimina la A1 actual",
+			changes: []PlanChange{
+				{Action: "remove", ResourceID: "ocid1.instance.arm"},
+				{Action: "remove", ResourceID: "ocid1.bootvolume.arm"},
+				{Type: "instance", OCPUs: 2, MemoryGB: 12, Count: 2},
+			},
+			wantFits: true,
+		},
+		{
+			name:         "volumen demasiado grande",
+			changes:      []PlanChange{{Type: "volume", SizeGB: 150}},
+			wantExceeded: "blockStorage.total exceeded by 50 GB",
+		},
+		{
+			name:         "tercera Micro",
+			changes:      []PlanChange{{Type: "instance", Shape: microShape, Count: 2}},
+			wantExceeded: "compute.amd.instances exceeded by 1",
+		},
+		{name: "shape de pago", changes: []PlanChange{{Type: "instance", Shape: "VM.Standard.E4.Flex", OCPUs: 1, MemoryGB: 8}}, wantErr: "not Always Free"},
+		{name: "bare metal A1", changes: []PlanChange{{Type: "instance", Shape: "BM.Standard.A1.160", OCPUs: 1, MemoryGB: 8}}, wantErr: "not Always Free"},
+		{name: "recurso inexistente", changes: []PlanChange{{Action: "remove", ResourceID: "ocid1.instance.none"}}, wantErr: "not found"},
+		{name: "tipo desconocido", changes: []PlanChange{{Type: "bucket"}}, wantErr: "unknown type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := evaluatePlan(compute, storage, currentLimits(), tt.changes)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v; want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Fits != tt.wantFits {
+				t.Errorf("Fits = %v; want %v (%+v)", result.Fits, tt.wantFits, result)
+			}
+			if tt.wantExceeded != "" && !strings.Contains(strings.Join(result.Exceeded, "; "), tt.wantExceeded) {
+				t.Errorf("Exceeded = %v; want %q", result.Exceeded, tt.wantExceeded)
+			}
+			if !result.Fits && len(result.Suggestions) != 0 {
+				t.Errorf("suggestions for a plan that does not fit: %+v", result.Suggestions)
+			}
+		})
+	}
+}
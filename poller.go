@@ -0,0 +1,179 @@
+// Package main - Este archivo contiene el recolector en segundo plano que mantiene
+// una copia en caché del uso de OCI
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// UsagePoller refresca periódicamente un snapshot de AllUsage
+// Los handlers leen la copia en caché en lugar de llamar a OCI en cada request
+type UsagePoller struct {
+	interval time.Duration
+	collect  func(ctx context.Context) (*AllUsage, error)
+
+	// listeners se ejecutan tras cada recolección correcta (histórico, alertas...)
+	listeners []func(usage *AllUsage, collectedAt time.Time)
+
+	// mu protege el snapshot y sus metadatos
+	mu          sync.RWMutex
+	snapshot    *AllUsage
+	collectedAt time.Time
+	lastErr     error
+
+	// refreshMu protege inflight, la recolección que está en curso
+	refreshMu sync.Mutex
+	inflight  *refreshCall
+}
+
+// refreshCall representa una recolección en curso que varios llamadores pueden esperar
+// La recolección se cancela solo cuando todos los que la esperan se han ido
+type refreshCall struct {
+	done        chan struct{}
+	cancel      context.CancelFunc
+	waiters     int // protegido por refreshMu
+	usage       *AllUsage
+	collectedAt time.Time
+	err         error
+}
+
+// errRefreshAbandoned indica que la recolección se canceló porque nadie la esperaba
+var errRefreshAbandoned = errors.New("usage collection cancelled: no callers left")
+
+// newUsagePoller crea un poller que usa collect para obtener el uso
+func newUsagePoller(interval time.Duration, collect func(ctx context.Context) (*AllUsage, error)) *UsagePoller {
+	return &UsagePoller{
+		interval: interval,
+		collect:  collect,
+	}
+}
+
+// OnCollect registra una función que se llama tras cada recolección correcta
+// Debe registrarse antes de Start
+func (p *UsagePoller) OnCollect(fn func(usage *AllUsage, collectedAt time.Time)) {
+	p.listeners = append(p.listeners, fn)
+}
+
+// Start lanza el bucle de refresco en una goroutine
+// Hace una primera recolección inmediata y luego una cada interval
+// Cerrar stop cancela también la recolección en curso
+func (p *UsagePoller) Start(stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer cancel()
+		p.Refresh(ctx)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.Refresh(ctx)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Refresh fuerza una recolección nueva
+// Si ya hay una en curso, espera a que termine en lugar de lanzar otra
+// Si ctx se cancela (por ejemplo, el cliente HTTP se desconecta) Refresh vuelve en el
+// momento; la recolección sigue mientras quede alguien esperándola
+func (p *UsagePoller) Refresh(ctx context.Context) (*AllUsage, time.Time, error) {
+	p.refreshMu.Lock()
+	call := p.inflight
+	if call == nil {
+		// La recolección usa su propio contexto: no depende de quien la lanzó
+		collectCtx, cancel := context.WithCancel(context.Background())
+		call = &refreshCall{done: make(chan struct{}), cancel: cancel}
+		p.inflight = call
+		go p.run(collectCtx, call)
+	}
+	call.waiters++
+	p.refreshMu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.usage, call.collectedAt, call.err
+	case <-ctx.Done():
+		p.refreshMu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			// La recolección cancelada ya no sirve: el siguiente Refresh lanza una nueva
+			// en lugar de unirse a esta y recibir errRefreshAbandoned
+			if p.inflight == call {
+				p.inflight = nil
+			}
+		}
+		p.refreshMu.Unlock()
+		return nil, time.Time{}, ctx.Err()
+	}
+}
+
+// run ejecuta la recolección de call y publica el resultado
+func (p *UsagePoller) run(ctx context.Context, call *refreshCall) {
+	defer call.cancel()
+
+	start := time.Now()
+	call.usage, call.err = p.collect(ctx)
+	call.collectedAt = time.Now().UTC()
+
+	// Un resultado a medias por cancelación no debe sustituir al snapshot bueno ni
+	// dejar su error en lastErr
+	if ctx.Err() != nil {
+		call.usage, call.err = nil, errRefreshAbandoned
+	}
+
+	p.mu.Lock()
+	// Una recolección abandonada no es un fallo de OCI: no pisa el error de la última real
+	if call.err != errRefreshAbandoned {
+		p.lastErr = call.err
+	}
+	if call.err == nil {
+		p.snapshot = call.usage
+		p.collectedAt = call.collectedAt
+	}
+	p.mu.Unlock()
+
+	if call.err != nil {
+		logger.Error().Err(call.err).Msg("Usage collection failed")
+	} else {
+		logger.Debug().Dur("duration", time.Since(start)).Msg("Usage snapshot refreshed")
+		for _, fn := range p.listeners {
+			fn(call.usage, call.collectedAt)
+		}
+	}
+
+	// Si call se abandonó, inflight puede apuntar ya a otra recolección
+	p.refreshMu.Lock()
+	if p.inflight == call {
+		p.inflight = nil
+	}
+	p.refreshMu.Unlock()
+	close(call.done)
+}
+
+// Snapshot devuelve el último uso recolectado y cuándo se obtuvo
+// Con force=true, o si todavía no hay ningún snapshot, recolecta en el momento
+func (p *UsagePoller) Snapshot(ctx context.Context, force bool) (*AllUsage, time.Time, error) {
+	if !force {
+		p.mu.RLock()
+		usage, collectedAt := p.snapshot, p.collectedAt
+		p.mu.RUnlock()
+		if usage != nil {
+			return usage, collectedAt, nil
+		}
+	}
+	return p.Refresh(ctx)
+}
+
+// snapshotAge calcula la antigüedad de un snapshot en segundos
+func snapshotAge(collectedAt time.Time) int {
+	return int(time.Since(collectedAt).Seconds())
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestUsagePollerCollapsesConcurrentRefreshes verifica que varias peticiones
+// simultáneas comparten una única recolección
+func TestUsagePollerCollapsesConcurrentRefreshes(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+
+	p := newUsagePoller(time.Minute, func(ctx context.Context) (*AllUsage, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return &AllUsage{PublicIPs: UsageMetric{Used: 1, Limit: 2, Percentage: 50}}, nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]*AllUsage, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, _ = p.Snapshot(context.Background(), true)
+		}(i)
+	}
+
+	// Dar tiempo a que todas las goroutines se unan a la recolección en curso
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("collect called %d times; want 1", got)
+	}
+	for i, usage := range results {
+		if usage == nil || usage.PublicIPs.Used != 1 {
+			t.Errorf("result %d = %+v; want shared snapshot", i, usage)
+		}
+	}
+}
+
+// TestUsagePollerServesCachedSnapshot verifica que sin refresh se usa la caché
+func TestUsagePollerServesCachedSnapshot(t *testing.T) {
+	var calls int32
+	p := newUsagePoller(time.Minute, func(ctx context.Context) (*AllUsage, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return &AllUsage{PublicIPs: UsageMetric{Used: float64(n)}}, nil
+	})
+
+	tests := []struct {
+		name     string
+		force    bool
+		wantUsed float64
+	}{
+		{name: "primera lectura recolecta", force: false, wantUsed: 1},
+		{name: "segunda lectura usa la caché", force: false, wantUsed: 1},
+		{name: "refresh fuerza una recolección", force: true, wantUsed: 2},
+		{name: "la caché guarda el nuevo snapshot", force: false, wantUsed: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage, collectedAt, err := p.Snapshot(context.Background(), tt.force)
+			if err != nil {
+				t.Fatalf("Snapshot(%v) error = %v", tt.force, err)
+			}
+			if collectedAt.IsZero() {
+				t.Error("collectedAt is zero")
+			}
+			if usage.PublicIPs.Used != tt.wantUsed {
+				t.Errorf("Used = %v; want %v", usage.PublicIPs.Used, tt.wantUsed)
+			}
+		})
+	}
+}
+
+// TestUsagePollerKeepsSnapshotOnError verifica que un fallo no borra el último snapshot bueno
+func TestUsagePollerKeepsSnapshotOnError(t *testing.T) {
+	fail := false
+	p := newUsagePoller(time.Minute, func(ctx context.Context) (*AllUsage, error) {
+		if fail {
+			return nil, errors.New("boom")
+		}
+		return &AllUsage{PublicIPs: UsageMetric{Used: 1}}, nil
+	})
+
+	if _, _, err := p.Snapshot(context.Background(), true); err != nil {
+		t.Fatalf("first refresh error = %v", err)
+	}
+
+	fail = true
+	if _, _, err := p.Snapshot(context.Background(), true); err == nil {
+		t.Error("forced refresh should report the collection error")
+	}
+
+	usage, _, err := p.Snapshot(context.Background(), false)
+	if err != nil || usage == nil || usage.PublicIPs.Used != 1 {
+		t.Errorf("Snapshot(false) = %+v, %v; want previous snapshot", usage, err)
+	}
+}
+
+// TestUsagePollerCancelsAbandonedRefresh verifica que un llamador que se va no espera
+// a OCI, y que la recolección se cancela cuando ya nadie la espera
+func TestUsagePollerCancelsAbandonedRefresh(t *testing.T) {
+	cancelled := make(chan struct{})
+	p := newUsagePoller(time.Minute, func(ctx context.Context) (*AllUsage, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := p.Snapshot(ctx, true)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Snapshot() error = %v; want context.DeadlineExceeded", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("collection was not cancelled after the last caller left")
+	}
+}
+
+// TestUsagePollerKeepsRefreshWhileWaited verifica que la recolección sigue si otro
+// llamador la está esperando
+func TestUsagePollerKeepsRefreshWhileWaited(t *testing.T) {
+	release := make(chan struct{})
+	p := newUsagePoller(time.Minute, func(ctx context.Context) (*AllUsage, error) {
+		select {
+		case <-release:
+			return &AllUsage{PublicIPs: UsageMetric{Used: 1}}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+
+	result := make(chan error, 1)
+	go func() {
+		_, _, err := p.Snapshot(context.Background(), true)
+		result <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if _, _, err := p.Snapshot(ctx, true); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled Snapshot() error = %v; want context.Canceled", err)
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Errorf("remaining caller error = %v; want shared result", err)
+	}
+}
+
+// TestUsagePollerRefreshAfterAbandon verifica que un Refresh que llega mientras la
+// recolección abandonada termina lanza una nueva en lugar de heredar su error
+func TestUsagePollerRefreshAfterAbandon(t *testing.T) {
+	finish := make(chan struct{})
+	var calls atomic.Int32
+	p := newUsagePoller(time.Minute, func(ctx context.Context) (*AllUsage, error) {
+		if calls.Add(1) == 1 {
+			// La primera recolección tarda en terminar tras la cancelación
+			<-ctx.Done()
+			<-finish
+			return nil, ctx.Err()
+		}
+		return &AllUsage{PublicIPs: UsageMetric{Used: 1}}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := p.Refresh(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("first Refresh() error = %v; want context.DeadlineExceeded", err)
+	}
+
+	usage, _, err := p.Refresh(context.Background())
+	if err != nil || usage == nil || usage.PublicIPs.Used != 1 {
+		t.Errorf("second Refresh() = %+v, %v; want a fresh collection", usage, err)
+	}
+
+	// La recolección abandonada termina después y no pisa el resultado bueno
+	close(finish)
+	time.Sleep(20 * time.Millisecond)
+	p.mu.RLock()
+	lastErr, snapshot := p.lastErr, p.snapshot
+	p.mu.RUnlock()
+	if lastErr != nil || snapshot == nil {
+		t.Errorf("lastErr = %v, snapshot = %+v; want the fresh collection", lastErr, snapshot)
+	}
+}
@@ -0,0 +1,208 @@
+// Package main - Este archivo detecta instancias Always Free con riesgo de ser reclamadas
+// Oracle reclama una instancia Always Free si durante 7 días el percentil 95 de CPU,
+// el de red y (solo en A1) el de memoria se quedan por debajo del 20%
+package main
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+)
+
+// Riesgo de reclamación de una instancia
+const (
+	ReclamationOK          = "OK"
+	ReclamationAtRisk      = "AT_RISK"     // lleva algún día inactiva
+	ReclamationReclaimable = "RECLAIMABLE" // cumple la regla de Oracle: se puede reclamar ya
+	ReclamationUnknown     = "UNKNOWN"     // sin métricas (p. ej. sin el agente de Oracle Cloud)
+)
+
+const (
+	reclamationWindow      = 7 * 24 * time.Hour
+	reclamationIdlePercent = 20.0
+	// reclamationStep es el intervalo de las métricas: 2016 puntos por instancia en 7 días
+	reclamationStep = 5 * time.Minute
+)
+
+// reclamationMetrics son las consultas de oci_computeagent, una serie por instancia
+var reclamationMetrics = struct {
+	cpu, memory, network string
+}{
+	cpu:     "CpuUtilization[5m].mean()",
+	memory:  "MemoryUtilization[5m].mean()",
+	network: "NetworksBytesOut[5m].sum()",
+}
+
+// getReclamationRisk evalúa el riesgo de reclamación de las instancias Always Free
+// instances son las que encontró getComputeUsage (solo RUNNING); las de pago no se reclaman
+func getReclamationRisk(ctx context.Context, backend ociBackend, compartmentID string, instances []InstanceInfo, now time.Time) (ReclamationUsage, error) {
+	usage := ReclamationUsage{Instances: []ReclamationRisk{}}
+
+	var candidates []InstanceInfo
+	for _, instance := range instances {
+		if instance.Arch == "arm" || instance.Arch == "amd" {
+			candidates = append(candidates, instance)
+		}
+	}
+	if len(candidates) == 0 {
+		return usage, nil
+	}
+
+	client, err := backend.Monitoring()
+	if err != nil {
+		usage.Error = err.Error()
+		return usage, err
+	}
+
+	// Una consulta por métrica para todo el compartimento, agrupando los puntos por instancia
+	start := now.Add(-reclamationWindow)
+	points := map[string]map[string][]monitoring.AggregatedDatapoint{}
+	for _, query := range []string{reclamationMetrics.cpu, reclamationMetrics.memory, reclamationMetrics.network} {
+		series, err := metricSeries(ctx, client, compartmentID, "oci_computeagent", query, "5m", start, now)
+		if err != nil {
+			usage.Error = err.Error()
+			return usage, err
+		}
+		points[query] = map[string][]monitoring.AggregatedDatapoint{}
+		for _, s := range series {
+			id := s.Dimensions["resourceId"]
+			points[query][id] = append(points[query][id], s.AggregatedDatapoints...)
+		}
+	}
+
+	for _, instance := range candidates {
+		usage.Instances = append(usage.Instances, assessReclamation(instance,
+			points[reclamationMetrics.cpu][instance.ID],
+			points[reclamationMetrics.memory][instance.ID],
+			networkUtilization(instance, points[reclamationMetrics.network][instance.ID]),
+			now))
+	}
+	return usage, nil
+}
+
+// networkUtilization convierte los bytes enviados en cada intervalo a % del ancho de banda de la shape
+// Si la shape no informa su ancho de banda se asume 1 Gbps por OCPU, como en A1
+func networkUtilization(instance InstanceInfo, bytes []monitoring.AggregatedDatapoint) []monitoring.AggregatedDatapoint {
+	gbps := instance.NetworkBandwidthGbps
+	if gbps <= 0 {
+		gbps = math.Max(instance.OCPUs, 1)
+	}
+	capacityBits := gbps * 1e9 * reclamationStep.Seconds()
+
+	utilization := make([]monitoring.AggregatedDatapoint, 0, len(bytes))
+	for _, point := range bytes {
+		if point.Value == nil {
+			continue
+		}
+		percent := *point.Value * 8 / capacityBits * 100
+		utilization = append(utilization, monitoring.AggregatedDatapoint{Timestamp: point.Timestamp, Value: &percent})
+	}
+	return utilization
+}
+
+// assessReclamation aplica la regla de Oracle a los puntos de 7 días de una instancia
+// network ya viene en % del ancho de banda (ver networkUtilization)
+func assessReclamation(instance InstanceInfo, cpu, memory, network []monitoring.AggregatedDatapoint, now time.Time) ReclamationRisk {
+	risk := ReclamationRisk{
+		InstanceID:    instance.ID,
+		Name:          instance.Name,
+		Shape:         instance.Shape,
+		Risk:          ReclamationUnknown,
+		CompartmentID: instance.CompartmentID,
+		Region:        instance.Region,
+	}
+	// La memoria solo cuenta en las shapes A1
+	usesMemory := instance.Arch == "arm"
+
+	risk.CPUP95 = percentile95(cpu)
+	risk.NetworkP95 = percentile95(network)
+	if usesMemory {
+		risk.MemoryP95 = percentile95(memory)
+	}
+	if risk.CPUP95 == nil || risk.NetworkP95 == nil || (usesMemory && risk.MemoryP95 == nil) {
+		return risk
+	}
+
+	// Días inactivos seguidos contando hacia atrás desde ahora (cada "día" son 24h)
+	for day := 0; day < 7; day++ {
+		to := now.Add(-time.Duration(day) * 24 * time.Hour)
+		from := to.Add(-24 * time.Hour)
+		idle := isIdle(percentile95(pointsBetween(cpu, from, to)), percentile95(pointsBetween(network, from, to)))
+		if usesMemory {
+			idle = idle && isIdle(percentile95(pointsBetween(memory, from, to)))
+		}
+		if !idle {
+			break
+		}
+		risk.IdleDays++
+	}
+
+	// La regla se aplica a la ventana completa: hace falta tener datos de (casi) 7 días
+	idle := isIdle(risk.CPUP95, risk.NetworkP95) && (!usesMemory || isIdle(risk.MemoryP95))
+	if idle && oldestPoint(cpu).Before(now.Add(-6*24*time.Hour)) {
+		risk.Risk = ReclamationReclaimable
+		risk.IdleDays = 7
+		risk.DaysRemaining = 0
+		return risk
+	}
+
+	risk.DaysRemaining = 7 - risk.IdleDays
+	if risk.IdleDays > 0 {
+		risk.Risk = ReclamationAtRisk
+	} else {
+		risk.Risk = ReclamationOK
+	}
+	return risk
+}
+
+// isIdle indica si todos los percentiles están por debajo del 20% (nil cuenta como no inactivo)
+func isIdle(values ...*float64) bool {
+	for _, value := range values {
+		if value == nil || *value >= reclamationIdlePercent {
+			return false
+		}
+	}
+	return true
+}
+
+// percentile95 calcula el percentil 95 (método nearest-rank); nil si no hay puntos
+func percentile95(points []monitoring.AggregatedDatapoint) *float64 {
+	var values []float64
+	for _, point := range points {
+		if point.Value != nil {
+			values = append(values, *point.Value)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Float64s(values)
+	rank := int(math.Ceil(0.95*float64(len(values)))) - 1
+	p95 := values[rank]
+	return &p95
+}
+
+// pointsBetween devuelve los puntos con timestamp en (from, to]
+func pointsBetween(points []monitoring.AggregatedDatapoint, from, to time.Time) []monitoring.AggregatedDatapoint {
+	var selected []monitoring.AggregatedDatapoint
+	for _, point := range points {
+		if point.Timestamp != nil && point.Timestamp.After(from) && !point.Timestamp.After(to) {
+			selected = append(selected, point)
+		}
+	}
+	return selected
+}
+
+// oldestPoint devuelve el timestamp más antiguo (el instante cero si no hay puntos)
+func oldestPoint(points []monitoring.AggregatedDatapoint) time.Time {
+	var oldest time.Time
+	for _, point := range points {
+		if point.Timestamp != nil && (oldest.IsZero() || point.Timestamp.Before(oldest)) {
+			oldest = point.Timestamp.Time
+		}
+	}
+	return oldest
+}
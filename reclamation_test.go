@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/monitoring"
+)
+
+// hourlyPoints genera un punto por hora durante los últimos days días
+// value recibe el día (0 = las últimas 24h) y devuelve el valor de ese día
+func hourlyPoints(now time.Time, days int, value func(day int) float64) []monitoring.AggregatedDatapoint {
+	var points []monitoring.AggregatedDatapoint
+	for h := 0; h < days*24; h++ {
+		v := value(h / 24)
+		points = append(points, monitoring.AggregatedDatapoint{
+			Timestamp: &common.SDKTime{Time: now.Add(-time.Duration(h) * time.Hour)},
+			Value:     &v,
+		})
+	}
+	return points
+}
+
+// constant devuelve siempre el mismo valor
+func constant(v float64) func(int) float64 { return func(int) float64 { return v } }
+
+func TestAssessReclamation(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	arm := InstanceInfo{ID: "ocid1.instance.arm", Name: "arm-1", Arch: "arm", OCPUs: 4}
+	micro := InstanceInfo{ID: "ocid1.instance.micro", Name: "micro-1", Arch: "amd", OCPUs: 1}
+	idle := hourlyPoints(now, 7, constant(5))
+	busy := hourlyPoints(now, 7, constant(35))
+
+	tests := []struct {
+		name          string
+		instance      InstanceInfo
+		cpu, mem, net []monitoring.AggregatedDatapoint
+		wantRisk      string
+		wantIdleDays  int
+		wantRemaining int
+	}{
+		{name: "CPU alta", instance: arm, cpu: busy, mem: idle, net: idle, wantRisk: ReclamationOK, wantRemaining: 7},
+		{name: "todo inactivo 7 días", instance: arm, cpu: idle, mem: idle, net: idle, wantRisk: ReclamationReclaimable, wantIdleDays: 7},
+		{
+			name:     "inactiva los últimos 3 días",
+			instance: arm,
+			cpu: hourlyPoints(now, 7, func(day int) float64 {
+				if day < 3 {
+					return 5
+				}
+				return 90
+			}),
+			mem: idle, net: idle,
+			wantRisk: ReclamationAtRisk, wantIdleDays: 3, wantRemaining: 4,
+		},
+		{name: "la memoria salva una A1", instance: arm, cpu: idle, mem: busy, net: idle, wantRisk: ReclamationOK, wantRemaining: 7},
+		{name: "en Micro la memoria no cuenta", instance: micro, cpu: idle, mem: busy, net: idle, wantRisk: ReclamationReclaimable, wantIdleDays: 7},
+		{name: "la red salva la instancia", instance: micro, cpu: idle, net: busy, wantRisk: ReclamationOK, wantRemaining: 7},
+		{name: "sin métricas", instance: arm, wantRisk: ReclamationUnknown},
+		{
+			name:     "instancia nueva con 2 días de datos",
+			instance: arm,
+			cpu:      hourlyPoints(now, 2, constant(5)), mem: hourlyPoints(now, 2, constant(5)), net: hourlyPoints(now, 2, constant(5)),
+			wantRisk: ReclamationAtRisk, wantIdleDays: 2, wantRemaining: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := assessReclamation(tt.instance, tt.cpu, tt.mem, tt.net, now)
+			if got.Risk != tt.wantRisk || got.IdleDays != tt.wantIdleDays || got.DaysRemaining != tt.wantRemaining {
+				t.Errorf("assessReclamation() = %s, %d idle, %d remaining; want %s, %d, %d",
+					got.Risk, got.IdleDays, got.DaysRemaining, tt.wantRisk, tt.wantIdleDays, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestPercentile95(t *testing.T) {
+	var points []monitoring.AggregatedDatapoint
+	for i := 1; i <= 100; i++ {
+		v := float64(i)
+		points = append(points, monitoring.AggregatedDatapoint{Value: &v})
+	}
+	if got := percentile95(points); got == nil || *got != 95 {
+		t.Errorf("percentile95(1..100) = %v; want 95", got)
+	}
+	if got := percentile95(nil); got != nil {
+		t.Errorf("percentile95(nil) = %v; want nil", *got)
+	}
+}
+
+// TestGetReclamationRisk verifica las consultas a Monitoring y el cálculo del % de red
+func TestGetReclamationRisk(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	series := func(name, id string, points []monitoring.AggregatedDatapoint) []monitoring.MetricData {
+		return []monitoring.MetricData{{
+			Namespace:            common.String("oci_computeagent"),
+			Name:                 common.String(name),
+			CompartmentId:        common.String(testCompartment),
+			Dimensions:           map[string]string{"resourceId": id},
+			AggregatedDatapoints: points,
+		}}
+	}
+	// 1 Gbps durante 5 minutos son 37,5 GB: 10 GB por intervalo es un ~27% de la red
+	scenario := &ociScenario{Metrics: map[string][]monitoring.MetricData{
+		"oci_computeagent/CpuUtilization":    series("CpuUtilization", "ocid1.instance.arm", hourlyPoints(now, 7, constant(2))),
+		"oci_computeagent/MemoryUtilization": series("MemoryUtilization", "ocid1.instance.arm", hourlyPoints(now, 7, constant(10))),
+		"oci_computeagent/NetworksBytesOut":  series("NetworksBytesOut", "ocid1.instance.arm", hourlyPoints(now, 7, constant(10e9))),
+	}}
+	instances := []InstanceInfo{
+		{ID: "ocid1.instance.arm", Name: "arm-1", Arch: "arm", OCPUs: 1, NetworkBandwidthGbps: 1, CompartmentID: testCompartment},
+		{ID: "ocid1.instance.paid", Name: "paid", Arch: "other"},
+	}
+
+	usage, err := getReclamationRisk(context.Background(), newScenarioBackend(scenario), testCompartment, instances, now)
+	if err != nil {
+		t.Fatalf("getReclamationRisk() error = %v", err)
+	}
+	if len(usage.Instances) != 1 {
+		t.Fatalf("Instances = %+v; want only the Always Free one", usage.Instances)
+	}
+	got := usage.Instances[0]
+	if got.Risk != ReclamationOK || got.NetworkP95 == nil || *got.NetworkP95 < 26 || *got.NetworkP95 > 27 {
+		t.Errorf("risk = %+v; want OK with ~26.7%% network", got)
+	}
+
+	// Sin tráfico, la misma instancia cumple la regla de reclamación y el estado es CRITICAL
+	scenario.Metrics["oci_computeagent/NetworksBytesOut"] = series("NetworksBytesOut", "ocid1.instance.arm", hourlyPoints(now, 7, constant(1e6)))
+	usage, err = getReclamationRisk(context.Background(), newScenarioBackend(scenario), testCompartment, instances, now)
+	if err != nil {
+		t.Fatalf("getReclamationRisk() error = %v", err)
+	}
+	if eval := evaluateUsage(&AllUsage{Reclamation: usage}); eval.Status != SeverityCritical {
+		t.Errorf("status = %s; want CRITICAL (%+v)", eval.Status, eval.Findings)
+	}
+}
@@ -0,0 +1,242 @@
+// Package main - Este archivo recolecta el uso en todas las regiones suscritas
+// Los recursos Always Free solo existen en la región principal (home region):
+// cualquier recurso en otra región se factura
+package main
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// Region es una región a recolectar
+type Region struct {
+	Name string
+	Home bool
+}
+
+// RegionUsage es el desglose de uso de una región en /usage
+type RegionUsage struct {
+	Region string             `json:"region"`
+	Home   bool               `json:"home"`
+	Used   map[string]float64 `json:"used"` // ruta de la métrica → uso
+	Errors []CollectorError   `json:"errors,omitempty"`
+}
+
+// regionScanAll indica si hay que recolectar todas las regiones suscritas
+// OCI_REGION_SCAN=all; por defecto solo se mira OCI_REGION
+func regionScanAll() bool {
+	return strings.EqualFold(os.Getenv("OCI_REGION_SCAN"), "all")
+}
+
+// regionProvider cambia la región de un ConfigurationProvider
+// En Go, embeber una interfaz permite "heredar" sus métodos y sobrescribir solo uno
+type regionProvider struct {
+	common.ConfigurationProvider
+	region string
+}
+
+// Region devuelve la región forzada
+func (p regionProvider) Region() (string, error) {
+	return p.region, nil
+}
+
+// resolveRegions devuelve las regiones a recolectar
+// Por defecto es solo region (la del proveedor de autenticación); en modo all son todas
+// las suscritas y listas (READY), con la principal primero
+func resolveRegions(ctx context.Context, backend ociBackend, region string) ([]Region, error) {
+	if !regionScanAll() {
+		return []Region{{Name: region}}, nil
+	}
+
+	client, err := backend.Identity()
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.ListRegionSubscriptions(ctx, identity.ListRegionSubscriptionsRequest{
+		TenancyId: common.String(getTenancyID()),
+	})
+	if err != nil {
+		return nil, ociError("identity", "ListRegionSubscriptions", err)
+	}
+	return subscribedRegions(response.Items), nil
+}
+
+// subscribedRegions filtra las suscripciones listas y pone la región principal primero
+func subscribedRegions(subscriptions []identity.RegionSubscription) []Region {
+	var regions []Region
+	for _, sub := range subscriptions {
+		if sub.RegionName == nil || sub.Status != identity.RegionSubscriptionStatusReady {
+			continue
+		}
+		regions = append(regions, Region{
+			Name: *sub.RegionName,
+			Home: sub.IsHomeRegion != nil && *sub.IsHomeRegion,
+		})
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		if regions[i].Home != regions[j].Home {
+			return regions[i].Home
+		}
+		return regions[i].Name < regions[j].Name
+	})
+	return regions
+}
+
+// homeRegion devuelve el nombre de la región principal de un snapshot multi-región
+func homeRegion(usage *AllUsage) string {
+	for _, region := range usage.Regions {
+		if region.Home {
+			return region.Region
+		}
+	}
+	return ""
+}
+
+// collectRegions recolecta cada región y junta los resultados
+// Con una sola región el resultado es idéntico al de collectCompartments
+func collectRegions(ctx context.Context, provider common.ConfigurationProvider, regions []Region, compartments []Compartment, pagination paginationConfig, timeouts timeoutConfig) *AllUsage {
+	if len(regions) == 1 && !regionScanAll() {
+		return collectCompartments(ctx, newOCIBackend(provider), compartments, pagination, timeouts)
+	}
+
+	parts := make([]*AllUsage, len(regions))
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		wg.Add(1)
+		go func(i int, region Region) {
+			defer wg.Done()
+			backend := newOCIBackend(regionProvider{provider, region.Name})
+			part := collectCompartments(ctx, backend, compartments, pagination, timeouts)
+			stampRegion(part, region.Name)
+			parts[i] = part
+		}(i, region)
+	}
+	wg.Wait()
+
+	// La asignación Always Free solo existe en la región principal: los totales y porcentajes
+	// salen solo de ella; del resto se añaden los recursos y errores, para el hallazgo CRITICAL
+	var home, others []*AllUsage
+	for i, region := range regions {
+		if region.Home {
+			home = append(home, parts[i])
+		} else {
+			others = append(others, parts[i])
+		}
+	}
+	var usage *AllUsage
+	if len(home) == 0 {
+		// Sin región principal conocida no hay nada mejor que sumar todas
+		usage = mergeUsage(parts...)
+	} else {
+		usage = mergeUsage(home...)
+		for _, part := range others {
+			mergeLists(usage, part)
+		}
+	}
+	usage.Compartments = combineCompartments(usage.Compartments)
+	for i, region := range regions {
+		usage.Regions = append(usage.Regions, RegionUsage{
+			Region: region.Name,
+			Home:   region.Home,
+			Used:   usedByPath(parts[i]),
+			Errors: parts[i].Errors,
+		})
+	}
+	return usage
+}
+
+// stampRegion anota la región en cada recurso y error de un snapshot parcial
+func stampRegion(usage *AllUsage, region string) {
+	for i := range usage.Compute.Instances {
+		usage.Compute.Instances[i].Region = region
+	}
+	for i := range usage.BlockStorage.Volumes {
+		usage.BlockStorage.Volumes[i].Region = region
+	}
+	for i := range usage.ObjectStorage.Buckets {
+		usage.ObjectStorage.Buckets[i].Region = region
+	}
+	for i := range usage.LoadBalancer.LoadBalancers {
+		usage.LoadBalancer.LoadBalancers[i].Region = region
+	}
+	for i := range usage.Database.Databases {
+		usage.Database.Databases[i].Region = region
+	}
+	for i := range usage.Reclamation.Instances {
+		usage.Reclamation.Instances[i].Region = region
+	}
+	for i := range usage.Errors {
+		usage.Errors[i].Region = region
+	}
+	for i := range usage.Compartments {
+		for j := range usage.Compartments[i].Errors {
+			usage.Compartments[i].Errors[j].Region = region
+		}
+	}
+}
+
+// combineCompartments junta los desgloses del mismo compartimento en varias regiones
+func combineCompartments(compartments []CompartmentUsage) []CompartmentUsage {
+	var combined []CompartmentUsage
+	index := map[string]int{}
+	for _, c := range compartments {
+		i, ok := index[c.ID]
+		if !ok {
+			index[c.ID] = len(combined)
+			c.Used = copyUsed(c.Used)
+			combined = append(combined, c)
+			continue
+		}
+		for path, used := range c.Used {
+			combined[i].Used[path] += used
+		}
+		combined[i].Errors = append(combined[i].Errors, c.Errors...)
+	}
+	return combined
+}
+
+// copyUsed copia un mapa de uso para no modificar el original al sumar
+func copyUsed(used map[string]float64) map[string]float64 {
+	copied := make(map[string]float64, len(used))
+	for path, value := range used {
+		copied[path] = value
+	}
+	return copied
+}
+
+// resourcesInRegion lista los recursos (OCIDs o nombres de bucket) de una región
+func resourcesInRegion(usage *AllUsage, region string) []string {
+	var ids []string
+	for _, instance := range usage.Compute.Instances {
+		if instance.Region == region {
+			ids = append(ids, instance.ID)
+		}
+	}
+	for _, vol := range usage.BlockStorage.Volumes {
+		if vol.Region == region {
+			ids = append(ids, vol.ID)
+		}
+	}
+	for _, bucket := range usage.ObjectStorage.Buckets {
+		if bucket.Region == region {
+			ids = append(ids, bucket.Name)
+		}
+	}
+	for _, lb := range usage.LoadBalancer.LoadBalancers {
+		if lb.Region == region {
+			ids = append(ids, lb.ID)
+		}
+	}
+	for _, db := range usage.Database.Databases {
+		if db.Region == region {
+			ids = append(ids, db.ID)
+		}
+	}
+	return ids
+}
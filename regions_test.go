@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+func TestSubscribedRegions(t *testing.T) {
+	subscriptions := []identity.RegionSubscription{
+		{RegionName: common.String("us-ashburn-1"), Status: identity.RegionSubscriptionStatusReady, IsHomeRegion: common.Bool(false)},
+		{RegionName: common.String("uk-london-1"), Status: identity.RegionSubscriptionStatusInProgress, IsHomeRegion: common.Bool(false)},
+		{RegionName: common.String("eu-madrid-1"), Status: identity.RegionSubscriptionStatusReady, IsHomeRegion: common.Bool(true)},
+		{RegionName: common.String("eu-frankfurt-1"), Status: identity.RegionSubscriptionStatusReady, IsHomeRegion: common.Bool(false)},
+	}
+
+	want := []Region{
+		{Name: "eu-madrid-1", Home: true},
+		{Name: "eu-frankfurt-1"},
+		{Name: "us-ashburn-1"},
+	}
+	if got := subscribedRegions(subscriptions); !reflect.DeepEqual(got, want) {
+		t.Errorf("subscribedRegions() = %+v; want %+v", got, want)
+	}
+}
+
+// TestOutsideHomeFindings verifica que cualquier recurso fuera de la región principal es CRITICAL
+func TestOutsideHomeFindings(t *testing.T) {
+	tests := []struct {
+		name       string
+		regions    []RegionUsage
+		wantStatus string
+		wantIDs    []string
+	}{
+		{
+			name: "solo la región principal",
+			regions: []RegionUsage{
+				{Region: "eu-madrid-1", Home: true, Used: map[string]float64{"compute.arm.ocpus": 2}},
+				{Region: "us-ashburn-1", Used: map[string]float64{"compute.arm.ocpus": 0}},
+			},
+			wantStatus: SeverityOK,
+		},
+		{
+			name: "volumen en otra región",
+			regions: []RegionUsage{
+				{Region: "eu-madrid-1", Home: true, Used: map[string]float64{}},
+				{Region: "us-ashburn-1", Used: map[string]float64{"blockStorage.total": 50}},
+			},
+			wantStatus: SeverityCritical,
+			wantIDs:    []string{"ocid1.volume.ashburn"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := &AllUsage{Regions: tt.regions}
+			usage.BlockStorage.Volumes = []VolumeInfo{
+				{ID: "ocid1.volume.madrid", Region: "eu-madrid-1"},
+				{ID: "ocid1.volume.ashburn", Region: "us-ashburn-1"},
+			}
+
+			eval := evaluateUsage(usage)
+			if eval.Status != tt.wantStatus {
+				t.Fatalf("Status = %s; want %s", eval.Status, tt.wantStatus)
+			}
+			if tt.wantIDs == nil {
+				return
+			}
+			f := eval.Findings[0]
+			if f.Resource != "regions.us-ashburn-1" || !reflect.DeepEqual(f.ResourceIDs, tt.wantIDs) {
+				t.Errorf("finding = %+v; want us-ashburn-1 with %v", f, tt.wantIDs)
+			}
+			if f.Message != "Billable resources in us-ashburn-1 outside home region eu-madrid-1 (blockStorage.total)" {
+				t.Errorf("Message = %q", f.Message)
+			}
+		})
+	}
+}
+
+// TestCollectRegionsHomeTotals verifica que los totales de la Free Tier solo cuentan la
+// región principal: lo de otras regiones aparece en la lista de recursos y en su desglose
+func TestCollectRegionsHomeTotals(t *testing.T) {
+	fixture := loadFixture(t, "free_tier.json")
+	useFakeBackend(t, newScenarioBackend(fixture))
+	regions := []Region{{Name: "eu-madrid-1", Home: true}, {Name: "us-ashburn-1"}}
+	compartments := []Compartment{{ID: testCompartment, Path: "/"}}
+
+	single := collectCompartment(context.Background(), newScenarioBackend(fixture), testCompartment, testPagination, currentTimeouts())
+	usage := collectRegions(context.Background(), nil, regions, compartments, testPagination, currentTimeouts())
+
+	if usage.BlockStorage.Total != single.BlockStorage.Total || usage.Compute.ARM.Instances != single.Compute.ARM.Instances {
+		t.Errorf("totals = %+v / %d ARM instances; want home region only %+v / %d",
+			usage.BlockStorage.Total, usage.Compute.ARM.Instances, single.BlockStorage.Total, single.Compute.ARM.Instances)
+	}
+	if got, want := len(usage.BlockStorage.Volumes), 2*len(single.BlockStorage.Volumes); got != want {
+		t.Errorf("len(Volumes) = %d; want %d (both regions)", got, want)
+	}
+	if eval := evaluateUsage(usage); eval.Status != SeverityCritical {
+		t.Errorf("Status = %s; want CRITICAL for resources in us-ashburn-1", eval.Status)
+	}
+}
+
+func TestCombineCompartments(t *testing.T) {
+	compartments := []CompartmentUsage{
+		{ID: "a", Path: "/", Used: map[string]float64{"blockStorage.total": 50}},
+		{ID: "b", Path: "/prod", Used: map[string]float64{"blockStorage.total": 100}},
+		{ID: "a", Path: "/", Used: map[string]float64{"blockStorage.total": 20}},
+	}
+
+	combined := combineCompartments(compartments)
+	if len(combined) != 2 {
+		t.Fatalf("got %d compartments; want 2", len(combined))
+	}
+	if got := combined[0].Used["blockStorage.total"]; got != 70 {
+		t.Errorf("root blockStorage.total = %v; want 70", got)
+	}
+	if compartments[0].Used["blockStorage.total"] != 50 {
+		t.Error("combineCompartments modified its input")
+	}
+}
+
+// TestResolveRegions verifica la región por defecto y la lista de suscripciones del doble de OCI
+func TestResolveRegions(t *testing.T) {
+	backend := newScenarioBackend(&ociScenario{RegionSubscriptions: []identity.RegionSubscription{
+		{RegionName: common.String("us-ashburn-1"), Status: identity.RegionSubscriptionStatusReady, IsHomeRegion: common.Bool(false)},
+		{RegionName: common.String("eu-madrid-1"), Status: identity.RegionSubscriptionStatusReady, IsHomeRegion: common.Bool(true)},
+	}})
+
+	tests := []struct {
+		name      string
+		scan      string
+		want      []Region
+		wantCalls int
+	}{
+		{name: "solo la región configurada", want: []Region{{Name: "eu-madrid-1"}}},
+		{name: "todas las suscritas", scan: "all", want: []Region{{Name: "eu-madrid-1", Home: true}, {Name: "us-ashburn-1"}}, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OCI_REGION_SCAN", tt.scan)
+			before := backend.callCount("ListRegionSubscriptions")
+			got, err := resolveRegions(context.Background(), backend, "eu-madrid-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveRegions() = %+v; want %+v", got, tt.want)
+			}
+			if calls := backend.callCount("ListRegionSubscriptions") - before; calls != tt.wantCalls {
+				t.Errorf("ListRegionSubscriptions calls = %d; want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
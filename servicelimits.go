@@ -0,0 +1,298 @@
+// Package main - Este archivo consulta los límites reales de la tenancy mediante el
+// servicio Limits de OCI, para compararlos con la asignación Always Free
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/limits"
+)
+
+// serviceLimitSpec relaciona un límite de OCI con la asignación Always Free equivalente
+type serviceLimitSpec struct {
+	resource  string // ruta de la UsageMetric equivalente
+	service   string
+	limitName string
+	allowance func(l FreeTierLimits) float64
+}
+
+// serviceLimitSpecs son los límites que se consultan
+var serviceLimitSpecs = []serviceLimitSpec{
+	{
+		resource:  "compute.arm.ocpus",
+		service:   "compute",
+		limitName: "standard-a1-core-count",
+		allowance: func(l FreeTierLimits) float64 { return l.Compute.ARM.OCPUs },
+	},
+	{
+		resource:  "compute.arm.memoryGB",
+		service:   "compute",
+		limitName: "standard-a1-memory-count",
+		allowance: func(l FreeTierLimits) float64 { return l.Compute.ARM.MemoryGB },
+	},
+	{
+		resource:  "blockStorage.total",
+		service:   "block-storage",
+		limitName: "total-storage-gb",
+		allowance: func(l FreeTierLimits) float64 { return float64(l.BlockStorage.TotalGB) },
+	},
+	{
+		resource:  "publicIPs",
+		service:   "vcn",
+		limitName: "reserved-public-ip-count",
+		allowance: func(l FreeTierLimits) float64 { return float64(l.PublicIPs.Reserved) },
+	},
+}
+
+// ScopedLimit es el valor de un límite en un ámbito (un AD o la región)
+type ScopedLimit struct {
+	Scope              string `json:"scope"` // "AD" o "REGION"
+	AvailabilityDomain string `json:"availabilityDomain,omitempty"`
+	Limit              int64  `json:"limit"`
+	Used               int64  `json:"used"`
+	Available          int64  `json:"available"`
+}
+
+// ServiceLimit compara el límite real de la tenancy con la asignación Always Free
+// En los límites por AD (como standard-a1-core-count) cada AD tiene su propio valor:
+// ServiceLimit y Available son los del AD con más margen, comparables con la asignación
+// Always Free; Used suma todos los ADs
+type ServiceLimit struct {
+	Resource          string        `json:"resource"`
+	Service           string        `json:"service"`
+	LimitName         string        `json:"limitName"`
+	FreeTierAllowance float64       `json:"freeTierAllowance"`
+	ServiceLimit      int64         `json:"serviceLimit"` // mayor límite de un ámbito
+	Used              int64         `json:"used"`
+	Available         int64         `json:"available"`
+	Drift             bool          `json:"drift"` // el límite real no coincide con la asignación gratuita
+	Scopes            []ScopedLimit `json:"scopes"`
+	Truncated         bool          `json:"truncated,omitempty"` // faltan ámbitos por OCI_MAX_PAGES
+	Error             string        `json:"error,omitempty"`
+}
+
+// ServiceLimitsReport es el resultado de consultar el servicio Limits
+type ServiceLimitsReport struct {
+	Region      string         `json:"region"`
+	CollectedAt string         `json:"collectedAt"`
+	Limits      []ServiceLimit `json:"limits"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// serviceLimitsCache evita consultar el servicio Limits en cada petición a /limits
+// Los límites de servicio cambian muy raramente
+var serviceLimitsCache = struct {
+	sync.Mutex
+	report    *ServiceLimitsReport
+	fetchedAt time.Time
+	inflight  *serviceLimitsCall // consulta en curso que comparten todos los llamadores
+}{}
+
+// serviceLimitsCall es una consulta al servicio Limits que varios llamadores pueden esperar
+type serviceLimitsCall struct {
+	done   chan struct{}
+	report *ServiceLimitsReport
+}
+
+// getServiceLimitsCached devuelve el informe en caché o lo recolecta si ha caducado
+// La consulta se hace fuera del mutex y una sola vez aunque lleguen varias peticiones,
+// igual que el poller de uso; si ctx se cancela se deja de esperar pero la consulta sigue
+func getServiceLimitsCached(ctx context.Context, ttl time.Duration, force bool) *ServiceLimitsReport {
+	serviceLimitsCache.Lock()
+	if !force && serviceLimitsCache.report != nil && time.Since(serviceLimitsCache.fetchedAt) < ttl {
+		report := serviceLimitsCache.report
+		serviceLimitsCache.Unlock()
+		return report
+	}
+	call := serviceLimitsCache.inflight
+	if call == nil {
+		call = &serviceLimitsCall{done: make(chan struct{})}
+		serviceLimitsCache.inflight = call
+		go fetchServiceLimits(call)
+	}
+	serviceLimitsCache.Unlock()
+
+	select {
+	case <-call.done:
+		return call.report
+	case <-ctx.Done():
+		return &ServiceLimitsReport{
+			Region:      getRegion(),
+			CollectedAt: time.Now().UTC().Format(time.RFC3339),
+			Limits:      []ServiceLimit{},
+			Error:       ctx.Err().Error(),
+		}
+	}
+}
+
+// fetchServiceLimits ejecuta la consulta de call y guarda el resultado en la caché
+// Usa su propio contexto: no depende del cliente HTTP que la lanzó
+func fetchServiceLimits(call *serviceLimitsCall) {
+	var report *ServiceLimitsReport
+	if provider, err := createConfigProvider(); err != nil {
+		report = &ServiceLimitsReport{
+			Region:      getRegion(),
+			CollectedAt: time.Now().UTC().Format(time.RFC3339),
+			Limits:      []ServiceLimit{},
+			Error:       err.Error(),
+		}
+	} else {
+		report = getServiceLimits(context.Background(), newOCIBackend(provider))
+	}
+	call.report = report
+
+	serviceLimitsCache.Lock()
+	// Un informe con errores (credenciales, red, OCI_TIMEOUT) no se guarda: si no, el fallo
+	// se repetiría durante todo SERVICE_LIMITS_TTL; la próxima petición lo reintenta
+	if report.complete() {
+		serviceLimitsCache.report = report
+		serviceLimitsCache.fetchedAt = time.Now()
+	}
+	serviceLimitsCache.inflight = nil
+	serviceLimitsCache.Unlock()
+	close(call.done)
+}
+
+// getServiceLimits consulta todos los límites de serviceLimitSpecs en paralelo
+// Se aplica el mismo tope global OCI_TIMEOUT que a los colectores de uso
+func getServiceLimits(ctx context.Context, backend ociBackend) *ServiceLimitsReport {
+	timeout := currentTimeouts().Global
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	report := &ServiceLimitsReport{
+		Region:      getRegion(),
+		CollectedAt: time.Now().UTC().Format(time.RFC3339),
+		Limits:      []ServiceLimit{},
+	}
+
+	client, err := backend.Limits()
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	// Los límites son de la tenancy: se consultan siempre sobre el compartimento raíz
+	tenancyID := getTenancyID()
+	freeTier := currentLimits()
+
+	results := make([]ServiceLimit, len(serviceLimitSpecs))
+	var wg sync.WaitGroup
+	for i, spec := range serviceLimitSpecs {
+		wg.Add(1)
+		go func(i int, spec serviceLimitSpec) {
+			defer wg.Done()
+			results[i] = getServiceLimit(ctx, client, tenancyID, spec, spec.allowance(freeTier))
+		}(i, spec)
+	}
+	wg.Wait()
+
+	report.Limits = results
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		report.Error = fmt.Sprintf("service limits timed out after %s", timeout)
+	}
+	return report
+}
+
+// complete indica si el informe se puede guardar en la caché: sin error general
+// (proveedor, cliente o timeout) ni fallos en ningún límite
+func (r *ServiceLimitsReport) complete() bool {
+	if r.Error != "" {
+		return false
+	}
+	for _, limit := range r.Limits {
+		if limit.Error != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// getServiceLimit obtiene el valor del límite en cada ámbito y su disponibilidad
+func getServiceLimit(ctx context.Context, client limitsAPI, tenancyID string, spec serviceLimitSpec, allowance float64) ServiceLimit {
+	result := ServiceLimit{
+		Resource:          spec.resource,
+		Service:           spec.service,
+		LimitName:         spec.limitName,
+		FreeTierAllowance: allowance,
+		Scopes:            []ScopedLimit{},
+	}
+
+	// ListLimitValues devuelve una entrada por AD (límites por AD) o una por región
+	pagination := currentPagination()
+	var values []limits.LimitValueSummary
+	truncated, err := pagination.paginate(func(page *string) (*string, error) {
+		response, err := client.ListLimitValues(ctx, limits.ListLimitValuesRequest{
+			CompartmentId: common.String(tenancyID),
+			ServiceName:   common.String(spec.service),
+			Name:          common.String(spec.limitName),
+			Limit:         common.Int(pagination.PageLimit),
+			Page:          page,
+		})
+		if err != nil {
+			return nil, ociError("limits", "ListLimitValues", err)
+		}
+		values = append(values, response.Items...)
+		return response.OpcNextPage, nil
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Truncated = truncated
+
+	for _, value := range values {
+		scoped := ScopedLimit{Scope: string(value.ScopeType)}
+		if value.Value != nil {
+			scoped.Limit = *value.Value
+		}
+
+		request := limits.GetResourceAvailabilityRequest{
+			ServiceName:   common.String(spec.service),
+			LimitName:     common.String(spec.limitName),
+			CompartmentId: common.String(tenancyID),
+		}
+		if value.ScopeType == limits.LimitValueSummaryScopeTypeAd && value.AvailabilityDomain != nil {
+			scoped.AvailabilityDomain = *value.AvailabilityDomain
+			request.AvailabilityDomain = value.AvailabilityDomain
+		}
+
+		availability, err := client.GetResourceAvailability(ctx, request)
+		if err != nil {
+			result.Error = ociError("limits", "GetResourceAvailability", err).Error()
+		} else {
+			if availability.Used != nil {
+				scoped.Used = *availability.Used
+			}
+			if availability.Available != nil {
+				scoped.Available = *availability.Available
+			}
+		}
+		result.Scopes = append(result.Scopes, scoped)
+	}
+
+	summarizeScopes(&result)
+	return result
+}
+
+// summarizeScopes resume los ámbitos de un límite y calcula el drift
+// Sumar los límites de cada AD daría, en una región con 3 ADs, el triple de la asignación
+// Always Free (que es de toda la tenancy) y siempre habría drift: se toma el mayor
+func summarizeScopes(result *ServiceLimit) {
+	result.ServiceLimit, result.Used, result.Available = 0, 0, 0
+	for _, scoped := range result.Scopes {
+		if scoped.Limit > result.ServiceLimit {
+			result.ServiceLimit = scoped.Limit
+		}
+		if scoped.Available > result.Available {
+			result.Available = scoped.Available
+		}
+		result.Used += scoped.Used
+	}
+	result.Drift = float64(result.ServiceLimit) != result.FreeTierAllowance
+}
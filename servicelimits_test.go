@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/limits"
+)
+
+func TestSummarizeScopes(t *testing.T) {
+	tests := []struct {
+		name          string
+		scopes        []ScopedLimit
+		allowance     float64
+		wantLimit     int64
+		wantUsed      int64
+		wantAvailable int64
+		wantDrift     bool
+	}{
+		{
+			name: "límite por AD igual a la asignación en cada AD",
+			scopes: []ScopedLimit{
+				{Scope: "AD", AvailabilityDomain: "AD-1", Limit: 4, Used: 2, Available: 2},
+				{Scope: "AD", AvailabilityDomain: "AD-2", Limit: 4, Used: 1, Available: 3},
+				{Scope: "AD", AvailabilityDomain: "AD-3", Limit: 4, Used: 0, Available: 4},
+			},
+			allowance: 4, wantLimit: 4, wantUsed: 3, wantAvailable: 4,
+		},
+		{
+			name: "cuenta PAYG con más límite en un AD",
+			scopes: []ScopedLimit{
+				{Scope: "AD", AvailabilityDomain: "AD-1", Limit: 80, Available: 80},
+				{Scope: "AD", AvailabilityDomain: "AD-2", Limit: 4, Available: 4},
+			},
+			allowance: 4, wantLimit: 80, wantAvailable: 80, wantDrift: true,
+		},
+		{
+			name:      "límite regional",
+			scopes:    []ScopedLimit{{Scope: "REGION", Limit: 200, Used: 150, Available: 50}},
+			allowance: 200, wantLimit: 200, wantUsed: 150, wantAvailable: 50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ServiceLimit{FreeTierAllowance: tt.allowance, Scopes: tt.scopes}
+			summarizeScopes(&result)
+			if result.ServiceLimit != tt.wantLimit || result.Used != tt.wantUsed || result.Available != tt.wantAvailable {
+				t.Errorf("limit/used/available = %d/%d/%d; want %d/%d/%d",
+					result.ServiceLimit, result.Used, result.Available, tt.wantLimit, tt.wantUsed, tt.wantAvailable)
+			}
+			if result.Drift != tt.wantDrift {
+				t.Errorf("Drift = %v; want %v", result.Drift, tt.wantDrift)
+			}
+		})
+	}
+}
+
+// TestGetServiceLimits consulta los límites de la tenancy con el doble de OCI
+func TestGetServiceLimits(t *testing.T) {
+	t.Setenv("OCI_TENANCY_ID", "ocid1.tenancy.oc1..test")
+	t.Setenv("OCI_REGION", "eu-madrid-1")
+	adLimit := func(ad string, limit, used, available int64) scenarioLimit {
+		return scenarioLimit{
+			LimitValueSummary: limits.LimitValueSummary{
+				ScopeType:          limits.LimitValueSummaryScopeTypeAd,
+				AvailabilityDomain: common.String(ad),
+				Value:              common.Int64(limit),
+			},
+			Used:      common.Int64(used),
+			Available: common.Int64(available),
+		}
+	}
+	scenario := &ociScenario{Limits: map[string][]scenarioLimit{
+		"compute/standard-a1-core-count": {adLimit("AD-1", 4, 2, 2), adLimit("AD-2", 4, 0, 4)},
+	}}
+
+	report := getServiceLimits(context.Background(), newScenarioBackend(scenario))
+	if report.Error != "" || !report.complete() {
+		t.Fatalf("report = %+v; want a complete report", report)
+	}
+	if len(report.Limits) != len(serviceLimitSpecs) {
+		t.Fatalf("len(Limits) = %d; want %d", len(report.Limits), len(serviceLimitSpecs))
+	}
+	ocpus := report.Limits[0]
+	if ocpus.LimitName != "standard-a1-core-count" || ocpus.ServiceLimit != 4 || ocpus.Used != 2 || ocpus.Available != 4 || ocpus.Drift {
+		t.Errorf("ocpus = %+v; want limit 4, used 2, available 4 without drift", ocpus)
+	}
+	if len(ocpus.Scopes) != 2 || ocpus.Scopes[1].AvailabilityDomain != "AD-2" {
+		t.Errorf("Scopes = %+v; want AD-1 and AD-2", ocpus.Scopes)
+	}
+}
+
+// TestServiceLimitsCache verifica que solo se guardan en la caché los informes sin errores
+func TestServiceLimitsCache(t *testing.T) {
+	clearOCIEnv(t)
+	setTestOCIEnv(t)
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyPath, []byte("unused"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OCI_PRIVATE_KEY_PATH", keyPath)
+	resetCache := func() {
+		serviceLimitsCache.Lock()
+		serviceLimitsCache.report = nil
+		serviceLimitsCache.Unlock()
+	}
+	resetCache()
+	t.Cleanup(resetCache)
+
+	scenario := &ociScenario{Errors: map[string]scenarioError{
+		"ListLimitValues": {Status: 401, Code: "NotAuthenticated", Message: "not authenticated"},
+	}}
+	backend := newScenarioBackend(scenario)
+	useFakeBackend(t, backend)
+
+	report := getServiceLimitsCached(context.Background(), time.Hour, false)
+	if report.complete() {
+		t.Fatalf("report = %+v; want limit errors", report)
+	}
+	if got := report.Limits[0].Error; !strings.HasPrefix(got, "limits ListLimitValues: ") {
+		t.Errorf("Limits[0].Error = %q; want the limits ListLimitValues error shape", got)
+	}
+
+	// El fallo no se guarda: la siguiente petición vuelve a consultar y guarda el informe bueno
+	scenario.Errors = nil
+	calls := backend.callCount("ListLimitValues")
+	if report := getServiceLimitsCached(context.Background(), time.Hour, false); !report.complete() {
+		t.Errorf("report = %+v; want a complete report once OCI answers", report)
+	}
+	if backend.callCount("ListLimitValues") == calls {
+		t.Error("failed report served from the cache")
+	}
+	calls = backend.callCount("ListLimitValues")
+	getServiceLimitsCached(context.Background(), time.Hour, false)
+	if backend.callCount("ListLimitValues") != calls {
+		t.Error("complete report not cached")
+	}
+}
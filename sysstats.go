@@ -0,0 +1,108 @@
+// Package main - Este archivo interpreta las estadísticas del sistema (/proc) que usa el
+// modo keepalive para medir la carga real de la máquina
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// cpuTimes son los contadores acumulados de la línea "cpu" de /proc/stat (en ticks)
+type cpuTimes struct {
+	Idle  uint64 // idle + iowait
+	Total uint64
+}
+
+// cpuPercent calcula el % de CPU ocupada entre dos lecturas
+func cpuPercent(before, after cpuTimes) float64 {
+	total := after.Total - before.Total
+	if after.Total <= before.Total {
+		return 0
+	}
+	idle := after.Idle - before.Idle
+	return float64(total-idle) / float64(total) * 100
+}
+
+// parseProcStat lee la línea agregada "cpu" de /proc/stat
+// Formato: cpu user nice system idle iowait irq softirq steal guest guest_nice
+func parseProcStat(r io.Reader) (cpuTimes, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		var times cpuTimes
+		// guest y guest_nice ya están incluidos en user y nice: no se suman
+		for i, field := range fields[1:] {
+			if i >= 8 {
+				break
+			}
+			value, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return cpuTimes{}, fmt.Errorf("invalid /proc/stat value %q: %w", field, err)
+			}
+			times.Total += value
+			if i == 3 || i == 4 { // idle, iowait
+				times.Idle += value
+			}
+		}
+		return times, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return cpuTimes{}, err
+	}
+	return cpuTimes{}, fmt.Errorf("no cpu line in /proc/stat")
+}
+
+// memoryInfo es la memoria total y disponible en bytes
+type memoryInfo struct {
+	Total     uint64
+	Available uint64
+}
+
+// usedPercent devuelve el % de memoria en uso
+func (m memoryInfo) usedPercent() float64 {
+	if m.Total == 0 {
+		return 0
+	}
+	return float64(m.Total-m.Available) / float64(m.Total) * 100
+}
+
+// parseMeminfo lee MemTotal y MemAvailable de /proc/meminfo (valores en kB)
+func parseMeminfo(r io.Reader) (memoryInfo, error) {
+	var info memoryInfo
+	found := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		var target *uint64
+		switch fields[0] {
+		case "MemTotal:":
+			target = &info.Total
+		case "MemAvailable:":
+			target = &info.Available
+		default:
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return memoryInfo{}, fmt.Errorf("invalid /proc/meminfo value %q: %w", fields[1], err)
+		}
+		*target = kb * 1024
+		found++
+	}
+	if err := scanner.Err(); err != nil {
+		return memoryInfo{}, err
+	}
+	if found < 2 {
+		return memoryInfo{}, fmt.Errorf("MemTotal or MemAvailable missing in /proc/meminfo")
+	}
+	return info, nil
+}
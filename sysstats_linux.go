@@ -0,0 +1,26 @@
+//go:build linux
+
+// Package main - Este archivo lee /proc para el modo keepalive (solo Linux)
+package main
+
+import "os"
+
+// readCPUTimes lee los contadores de CPU del sistema
+func readCPUTimes() (cpuTimes, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return cpuTimes{}, err
+	}
+	defer f.Close()
+	return parseProcStat(f)
+}
+
+// readMemoryInfo lee la memoria total y disponible del sistema
+func readMemoryInfo() (memoryInfo, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return memoryInfo{}, err
+	}
+	defer f.Close()
+	return parseMeminfo(f)
+}
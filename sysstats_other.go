@@ -0,0 +1,17 @@
+//go:build !linux
+
+// Package main - Fuera de Linux no hay /proc: el modo keepalive no puede medir la carga real
+package main
+
+import "errors"
+
+// errSysstatsUnsupported indica que el sistema operativo no expone /proc
+var errSysstatsUnsupported = errors.New("system statistics are only available on Linux")
+
+func readCPUTimes() (cpuTimes, error) {
+	return cpuTimes{}, errSysstatsUnsupported
+}
+
+func readMemoryInfo() (memoryInfo, error) {
+	return memoryInfo{}, errSysstatsUnsupported
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseProcStat(t *testing.T) {
+	input := "cpu  100 0 50 800 50 0 0 0 10 0\ncpu0 50 0 25 400 25 0 0 0 5 0\nintr 1234\n"
+	got, err := parseProcStat(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseProcStat: %v", err)
+	}
+	// guest (10) no se suma: ya va dentro de user
+	if got.Total != 1000 || got.Idle != 850 {
+		t.Errorf("got %+v, want Total=1000 Idle=850", got)
+	}
+
+	if _, err := parseProcStat(strings.NewReader("intr 1234\n")); err == nil {
+		t.Error("expected an error without cpu line")
+	}
+	if _, err := parseProcStat(strings.NewReader("cpu 1 x 3 4 5\n")); err == nil {
+		t.Error("expected an error with an invalid value")
+	}
+}
+
+func TestCPUPercent(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after cpuTimes
+		want          float64
+	}{
+		{name: "mitad ocupada", before: cpuTimes{Idle: 100, Total: 200}, after: cpuTimes{Idle: 150, Total: 300}, want: 50},
+		{name: "todo inactivo", before: cpuTimes{Idle: 100, Total: 200}, after: cpuTimes{Idle: 200, Total: 300}, want: 0},
+		{name: "sin ticks nuevos", before: cpuTimes{Idle: 100, Total: 200}, after: cpuTimes{Idle: 100, Total: 200}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cpuPercent(tt.before, tt.after); math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("cpuPercent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMeminfo(t *testing.T) {
+	input := "MemTotal:       1000 kB\nMemFree:         100 kB\nMemAvailable:    250 kB\nBuffers: 10 kB\n"
+	got, err := parseMeminfo(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseMeminfo: %v", err)
+	}
+	if got.Total != 1000*1024 || got.Available != 250*1024 {
+		t.Errorf("got %+v", got)
+	}
+	if p := got.usedPercent(); p != 75 {
+		t.Errorf("usedPercent = %v, want 75", p)
+	}
+
+	if _, err := parseMeminfo(strings.NewReader("MemTotal: 1000 kB\n")); err == nil {
+		t.Error("expected an error without MemAvailable")
+	}
+}
@@ -0,0 +1,178 @@
+// Package main - Este archivo contiene los umbrales de severidad configurables por recurso
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Thresholds son los porcentajes a partir de los cuales (>=) se alcanza cada severidad
+type Thresholds struct {
+	Attention int `json:"attention"`
+	Warning   int `json:"warning"`
+	Critical  int `json:"critical"`
+}
+
+// defaultThresholds son los cortes históricos 60/80/90
+var defaultThresholds = Thresholds{Attention: 60, Warning: 80, Critical: 90}
+
+// ThresholdConfig contiene los umbrales por defecto y los específicos por ruta de métrica
+type ThresholdConfig struct {
+	Default   Thresholds            `json:"default"`
+	Resources map[string]Thresholds `json:"resources"`
+}
+
+// thresholds es la configuración activa (se carga al arrancar)
+var thresholds = ThresholdConfig{Default: defaultThresholds, Resources: map[string]Thresholds{}}
+
+// For devuelve los umbrales aplicables a una ruta de métrica
+func (c ThresholdConfig) For(path string) Thresholds {
+	if t, ok := c.Resources[path]; ok {
+		return t
+	}
+	return c.Default
+}
+
+// Severity devuelve la severidad de un porcentaje y el umbral que la disparó
+func (t Thresholds) Severity(percentage int) (string, int) {
+	switch {
+	case percentage >= t.Critical:
+		return SeverityCritical, t.Critical
+	case percentage >= t.Warning:
+		return SeverityWarning, t.Warning
+	case percentage >= t.Attention:
+		return SeverityAttention, t.Attention
+	default:
+		return SeverityOK, 0
+	}
+}
+
+// validate comprueba que los niveles son positivos y crecientes
+func (t Thresholds) validate() error {
+	if t.Attention <= 0 || t.Warning <= 0 || t.Critical <= 0 {
+		return fmt.Errorf("thresholds must be positive: %+v", t)
+	}
+	if t.Attention > t.Warning || t.Warning > t.Critical {
+		return fmt.Errorf("thresholds must satisfy attention <= warning <= critical: %+v", t)
+	}
+	return nil
+}
+
+// loadThresholds construye la configuración a partir de un fichero JSON y/o de una
+// cadena tipo "blockStorage.total=60:80:95,compute.arm.ocpus=::101"
+// La cadena se aplica después del fichero, así que tiene prioridad
+func loadThresholds(path, spec string) (ThresholdConfig, error) {
+	config := ThresholdConfig{Default: defaultThresholds, Resources: map[string]Thresholds{}}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return config, fmt.Errorf("error reading thresholds file: %w", err)
+		}
+		if err := parseThresholdsJSON(data, &config); err != nil {
+			return config, fmt.Errorf("error parsing thresholds file %s: %w", path, err)
+		}
+	}
+
+	if spec != "" {
+		if err := parseThresholdsSpec(spec, &config); err != nil {
+			return config, err
+		}
+	}
+
+	if err := config.Default.validate(); err != nil {
+		return config, fmt.Errorf("default: %w", err)
+	}
+	known := flattenUsageMetrics(&AllUsage{})
+	for resource, t := range config.Resources {
+		if _, ok := known[resource]; !ok {
+			return config, fmt.Errorf("unknown metric %q (valid: %s)", resource, strings.Join(usageMetricPaths(), ", "))
+		}
+		if err := t.validate(); err != nil {
+			return config, fmt.Errorf("%s: %w", resource, err)
+		}
+	}
+
+	return config, nil
+}
+
+// parseThresholdsJSON lee el fichero; los campos que falten heredan del default
+func parseThresholdsJSON(data []byte, config *ThresholdConfig) error {
+	var raw struct {
+		Default   json.RawMessage            `json:"default"`
+		Resources map[string]json.RawMessage `json:"resources"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if raw.Default != nil {
+		if err := json.Unmarshal(raw.Default, &config.Default); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	for resource, value := range raw.Resources {
+		// Partir de los valores por defecto: json.Unmarshal solo pisa los campos presentes
+		t := config.Default
+		if err := json.Unmarshal(value, &t); err != nil {
+			return fmt.Errorf("%s: %w", resource, err)
+		}
+		config.Resources[resource] = t
+	}
+	return nil
+}
+
+// parseThresholdsSpec lee el formato "ruta=attention:warning:critical" separado por comas
+// Un nivel vacío mantiene el valor por defecto (ej. "::95")
+func parseThresholdsSpec(spec string, config *ThresholdConfig) error {
+	for _, entry := range splitList(spec) {
+		resource, levels, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid threshold %q: expected path=attention:warning:critical", entry)
+		}
+		resource = strings.TrimSpace(resource)
+
+		parts := strings.Split(levels, ":")
+		if len(parts) != 3 {
+			return fmt.Errorf("invalid threshold %q: expected three levels", entry)
+		}
+
+		t := config.Default
+		if existing, ok := config.Resources[resource]; ok {
+			t = existing
+		}
+		targets := []*int{&t.Attention, &t.Warning, &t.Critical}
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return fmt.Errorf("invalid threshold %q: %w", entry, err)
+			}
+			*targets[i] = value
+		}
+
+		if resource == "default" {
+			config.Default = t
+		} else {
+			config.Resources[resource] = t
+		}
+	}
+	return nil
+}
+
+// overriddenResources devuelve las rutas con umbrales propios, ordenadas (para logs)
+func (c ThresholdConfig) overriddenResources() []string {
+	resources := make([]string, 0, len(c.Resources))
+	for resource := range c.Resources {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	return resources
+}
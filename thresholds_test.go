@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadThresholds verifica el fichero JSON, la variable THRESHOLDS y la validación
+func TestLoadThresholds(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "thresholds.json")
+	content := `{"resources": {"blockStorage.total": {"critical": 95}}}`
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		spec    string
+		check   string
+		want    Thresholds
+		wantErr bool
+	}{
+		{
+			name:  "sin configuración usa 60/80/90",
+			check: "blockStorage.total",
+			want:  Thresholds{Attention: 60, Warning: 80, Critical: 90},
+		},
+		{
+			name:  "el fichero hereda los niveles no indicados",
+			path:  file,
+			check: "blockStorage.total",
+			want:  Thresholds{Attention: 60, Warning: 80, Critical: 95},
+		},
+		{
+			name:  "la variable mantiene niveles vacíos",
+			spec:  "compute.arm.ocpus=::101",
+			check: "compute.arm.ocpus",
+			want:  Thresholds{Attention: 60, Warning: 80, Critical: 101},
+		},
+		{
+			name:  "la variable tiene prioridad sobre el fichero",
+			path:  file,
+			spec:  "blockStorage.total=70:85:",
+			check: "blockStorage.total",
+			want:  Thresholds{Attention: 70, Warning: 85, Critical: 95},
+		},
+		{
+			name:  "default cambia el resto de recursos",
+			spec:  "default=50:75:85",
+			check: "publicIPs",
+			want:  Thresholds{Attention: 50, Warning: 75, Critical: 85},
+		},
+		{name: "métrica desconocida", spec: "compute.gpu=1:2:3", wantErr: true},
+		{name: "niveles no crecientes", spec: "publicIPs=90:80:70", wantErr: true},
+		{name: "formato inválido", spec: "publicIPs=90", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := loadThresholds(tt.path, tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadThresholds() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := config.For(tt.check); got != tt.want {
+				t.Errorf("For(%q) = %+v; want %+v", tt.check, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestThresholdsSeverity verifica qué nivel se dispara para cada porcentaje
+func TestThresholdsSeverity(t *testing.T) {
+	th := Thresholds{Attention: 60, Warning: 80, Critical: 101}
+
+	tests := []struct {
+		percentage    int
+		wantSeverity  string
+		wantThreshold int
+	}{
+		{percentage: 59, wantSeverity: SeverityOK, wantThreshold: 0},
+		{percentage: 60, wantSeverity: SeverityAttention, wantThreshold: 60},
+		{percentage: 100, wantSeverity: SeverityWarning, wantThreshold: 80},
+		{percentage: 125, wantSeverity: SeverityCritical, wantThreshold: 101},
+	}
+
+	for _, tt := range tests {
+		severity, threshold := th.Severity(tt.percentage)
+		if severity != tt.wantSeverity || threshold != tt.wantThreshold {
+			t.Errorf("Severity(%d) = %s, %d; want %s, %d",
+				tt.percentage, severity, threshold, tt.wantSeverity, tt.wantThreshold)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package main - Este archivo contiene los timeouts de las llamadas a OCI
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// collectorNames son los colectores de getOCIUsage, en el orden en que se informan
+var collectorNames = []string{"compute", "blockStorage", "objectStorage", "objectStorageRequests", "loadBalancer", "database", "bandwidth", "reclamation", "publicIPs"}
+
+// timeoutConfig controla cuánto puede tardar la recolección
+type timeoutConfig struct {
+	Global       time.Duration            // tope de toda la recolección (OCI_TIMEOUT)
+	PerCollector map[string]time.Duration // tope de cada colector (OCI_TIMEOUT_<COLECTOR>)
+}
+
+// currentTimeouts lee los timeouts del entorno
+// Los colectores sin timeout propio usan el global
+func currentTimeouts() timeoutConfig {
+	cfg := timeoutConfig{
+		Global:       getEnvDuration("OCI_TIMEOUT", 60*time.Second),
+		PerCollector: map[string]time.Duration{},
+	}
+	for _, name := range collectorNames {
+		cfg.PerCollector[name] = getEnvDuration(collectorTimeoutEnv(name), cfg.Global)
+	}
+	return cfg
+}
+
+// collectorTimeoutEnv devuelve la variable de entorno del timeout de un colector
+// Por ejemplo "blockStorage" → "OCI_TIMEOUT_BLOCKSTORAGE"
+func collectorTimeoutEnv(name string) string {
+	return "OCI_TIMEOUT_" + strings.ToUpper(name)
+}
+
+// For devuelve el timeout de un colector
+func (c timeoutConfig) For(name string) time.Duration {
+	if d, ok := c.PerCollector[name]; ok {
+		return d
+	}
+	return c.Global
+}
+
+// isTimeout indica si ctx terminó por agotar su plazo
+// En Go, context.DeadlineExceeded distingue un timeout de una cancelación
+func isTimeout(ctx context.Context) bool {
+	return ctx.Err() == context.DeadlineExceeded
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCurrentTimeouts verifica que cada collector usa su OCI_TIMEOUT_<COLLECTOR> o, si no hay, OCI_TIMEOUT
+func TestCurrentTimeouts(t *testing.T) {
+	t.Setenv("OCI_TIMEOUT", "30s")
+	t.Setenv("OCI_TIMEOUT_OBJECTSTORAGE", "2m")
+	t.Setenv("OCI_TIMEOUT_COMPUTE", "")
+
+	cfg := currentTimeouts()
+
+	tests := []struct {
+		name      string
+		collector string
+		want      time.Duration
+	}{
+		{name: "variable vacía usa el global", collector: "compute", want: 30 * time.Second},
+		{name: "variable propia del collector", collector: "objectStorage", want: 2 * time.Minute},
+		{name: "requests no heredan el de objectStorage", collector: "objectStorageRequests", want: 30 * time.Second},
+		{name: "sin variable propia usa el global", collector: "publicIPs", want: 30 * time.Second},
+		{name: "collector desconocido usa el global", collector: "unknown", want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.For(tt.collector); got != tt.want {
+				t.Errorf("For(%q) = %v; want %v", tt.collector, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Package main - Este archivo suma varios AllUsage (uno por compartimento o región)
+// en un único total comparado con los límites de la Free Tier
+package main
+
+import (
+	"reflect"
+	"strings"
+)
+
+// mergeUsage suma los snapshots parciales en uno nuevo
+// Los límites son de la tenancy, así que los porcentajes se recalculan sobre el total
+// Como flattenUsageMetrics, usa reflection: los campos nuevos se suman solos
+func mergeUsage(parts ...*AllUsage) *AllUsage {
+	merged := &AllUsage{}
+	for _, part := range parts {
+		if part == nil {
+			continue
+		}
+		mergeValue(reflect.ValueOf(merged).Elem(), reflect.ValueOf(*part))
+	}
+	finishMerge(reflect.ValueOf(merged).Elem())
+	return merged
+}
+
+// mergeValue acumula src sobre dst según el tipo de cada campo:
+// números se suman, bools se combinan con OR, slices se concatenan y
+// los textos (mensajes de error) se unen con "; "
+func mergeValue(dst, src reflect.Value) {
+	if dst.Type() == usageMetricType {
+		dst.FieldByName("Used").SetFloat(dst.FieldByName("Used").Float() + src.FieldByName("Used").Float())
+		if limit := src.FieldByName("Limit").Float(); limit > dst.FieldByName("Limit").Float() {
+			dst.FieldByName("Limit").SetFloat(limit)
+		}
+		return
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		for i := 0; i < dst.NumField(); i++ {
+			if dst.Type().Field(i).IsExported() {
+				mergeValue(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Int, reflect.Int64:
+		dst.SetInt(dst.Int() + src.Int())
+	case reflect.Float64:
+		dst.SetFloat(dst.Float() + src.Float())
+	case reflect.Bool:
+		dst.SetBool(dst.Bool() || src.Bool())
+	case reflect.Slice:
+		if src.Len() > 0 {
+			dst.Set(reflect.AppendSlice(dst, src))
+		} else if dst.IsNil() && !src.IsNil() {
+			dst.Set(reflect.MakeSlice(dst.Type(), 0, 0))
+		}
+	case reflect.String:
+		switch {
+		case src.String() == "":
+		case dst.String() == "":
+			dst.SetString(src.String())
+		default:
+			dst.SetString(dst.String() + "; " + src.String())
+		}
+	}
+}
+
+// mergeLists añade a dst los recursos, errores y listas de colectores de src sin tocar
+// sus totales: es mergeUsage solo para los slices
+func mergeLists(dst, src *AllUsage) {
+	if src == nil {
+		return
+	}
+	mergeSlices(reflect.ValueOf(dst).Elem(), reflect.ValueOf(*src))
+	finishMerge(reflect.ValueOf(dst).Elem())
+}
+
+// mergeSlices recorre los structs y concatena solo los slices
+func mergeSlices(dst, src reflect.Value) {
+	switch dst.Kind() {
+	case reflect.Struct:
+		if dst.Type() == usageMetricType {
+			return
+		}
+		for i := 0; i < dst.NumField(); i++ {
+			if dst.Type().Field(i).IsExported() {
+				mergeSlices(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		mergeValue(dst, src)
+	}
+}
+
+// finishMerge recalcula los porcentajes y quita duplicados de las listas de colectores
+func finishMerge(v reflect.Value) {
+	if v.Type() == usageMetricType {
+		used, limit := v.FieldByName("Used").Float(), v.FieldByName("Limit").Float()
+		percentage := 0
+		if limit > 0 {
+			percentage = int((used / limit) * 100)
+		}
+		v.FieldByName("Percentage").SetInt(int64(percentage))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				finishMerge(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			v.Set(reflect.ValueOf(uniqueStrings(v.Interface().([]string))))
+		}
+	}
+}
+
+// uniqueStrings quita duplicados conservando el orden
+func uniqueStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// usedByPath resume un snapshot como ruta de métrica → uso, para los desgloses
+func usedByPath(usage *AllUsage) map[string]float64 {
+	used := map[string]float64{}
+	for path, m := range flattenUsageMetrics(usage) {
+		used[path] = m.Used
+	}
+	return used
+}
+
+// metricCollector devuelve el colector que produce una métrica
+// El primer segmento de la ruta coincide con el nombre del colector, salvo las peticiones
+// de object storage, que cuenta objectStorageRequests con Monitoring
+func metricCollector(path string) string {
+	switch path {
+	case "objectStorage.requests", "objectStorage.projectedRequests":
+		return "objectStorageRequests"
+	}
+	return strings.Split(path, ".")[0]
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMergeUsage verifica que los parciales se suman y los porcentajes se recalculan
+func TestMergeUsage(t *testing.T) {
+	prod := &AllUsage{}
+	prod.Compute.ARM.OCPUs = UsageMetric{Used: 2, Limit: 4, Percentage: 50}
+	prod.Compute.ARM.Instances = 1
+	prod.Compute.Instances = []InstanceInfo{{ID: "ocid1.instance.prod", Arch: "arm"}}
+	prod.BlockStorage.Total = UsageMetric{Used: 100, Limit: 200, Percentage: 50}
+	prod.Truncated = []string{"blockStorage"}
+
+	lab := &AllUsage{}
+	lab.Compute.ARM.OCPUs = UsageMetric{Used: 1, Limit: 4, Percentage: 25}
+	lab.Compute.ARM.Instances = 1
+	lab.Compute.Instances = []InstanceInfo{{ID: "ocid1.instance.lab", Arch: "arm"}}
+	lab.BlockStorage.Total = UsageMetric{Used: 90, Limit: 200, Percentage: 45}
+	lab.BlockStorage.Error = "core ListVolumes: boom"
+	lab.Truncated = []string{"blockStorage"}
+	lab.Errors = []CollectorError{{Collector: "loadBalancer", Message: "boom"}}
+
+	merged := mergeUsage(prod, lab)
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"ARM OCPUs", merged.Compute.ARM.OCPUs, UsageMetric{Used: 3, Limit: 4, Percentage: 75}},
+		{"block storage", merged.BlockStorage.Total, UsageMetric{Used: 190, Limit: 200, Percentage: 95}},
+		{"contadores", merged.Compute.ARM.Instances, 2},
+		{"instancias", len(merged.Compute.Instances), 2},
+		{"errores de texto", merged.BlockStorage.Error, "core ListVolumes: boom"},
+		{"truncated sin duplicados", merged.Truncated, []string{"blockStorage"}},
+		{"errores estructurados", len(merged.Errors), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %+v; want %+v", tt.got, tt.want)
+			}
+		})
+	}
+
+	// Los parciales no se modifican
+	if prod.Compute.ARM.OCPUs.Used != 2 || len(prod.Compute.Instances) != 1 {
+		t.Error("mergeUsage modified its inputs")
+	}
+}
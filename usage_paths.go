@@ -0,0 +1,71 @@
+// Package main - Este archivo permite recorrer las métricas de AllUsage por su ruta JSON
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// usageMetricType se usa para reconocer los campos UsageMetric al recorrer AllUsage
+var usageMetricType = reflect.TypeOf(UsageMetric{})
+
+// flattenUsageMetrics devuelve todas las UsageMetric de un AllUsage indexadas por
+// su ruta JSON, por ejemplo "blockStorage.total" o "compute.arm.ocpus"
+// Usa reflection para no tener que mantener la lista a mano cuando se añaden recursos
+func flattenUsageMetrics(usage *AllUsage) map[string]UsageMetric {
+	metrics := map[string]UsageMetric{}
+	if usage == nil {
+		return metrics
+	}
+	walkUsageMetrics(reflect.ValueOf(*usage), "", metrics)
+	return metrics
+}
+
+// walkUsageMetrics recorre recursivamente los structs siguiendo los tags json
+func walkUsageMetrics(v reflect.Value, prefix string, out map[string]UsageMetric) {
+	if v.Type() == usageMetricType {
+		out[prefix] = v.Interface().(UsageMetric)
+		return
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		walkUsageMetrics(v.Field(i), path, out)
+	}
+}
+
+// jsonFieldName devuelve el nombre JSON de un campo (o el nombre Go si no tiene tag)
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// usageMetricPaths devuelve la lista ordenada de rutas de métricas conocidas
+func usageMetricPaths() []string {
+	metrics := flattenUsageMetrics(&AllUsage{})
+	paths := make([]string, 0, len(metrics))
+	for path := range metrics {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}